@@ -0,0 +1,299 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/kylelemons/godebug/diff"
+)
+
+// differ compares files and directories and writes out their differences.
+type differ struct {
+	options
+	out, errs io.Writer
+}
+
+// trouble reports an error and returns the corresponding exit status.
+func (d *differ) trouble(err error) int {
+	fmt.Fprintf(d.errs, "godiff: %s\n", err)
+	return exitTrouble
+}
+
+// compare compares the files or directories a and b and returns the exit
+// status.  The top argument is true for the paths given on the command line,
+// and header is true if a line naming the files is to be written before any
+// differences between regular files, as it is for files found in directories.
+func (d *differ) compare(a, b string, top, header bool) int {
+	aInfo, err := os.Stat(a)
+	if err != nil {
+		return d.trouble(err)
+	}
+	bInfo, err := os.Stat(b)
+	if err != nil {
+		return d.trouble(err)
+	}
+
+	switch {
+	case aInfo.IsDir() && bInfo.IsDir():
+		return d.compareDirs(a, b)
+	case top && aInfo.IsDir():
+		// Like GNU diff, compare a file with the same name in the directory,
+		// which stands in for the operand, so there is no header.
+		return d.compare(filepath.Join(a, filepath.Base(b)), b, false, header)
+	case top && bInfo.IsDir():
+		return d.compare(a, filepath.Join(b, filepath.Base(a)), false, header)
+	case aInfo.IsDir():
+		fmt.Fprintf(d.out, "File %s is a directory while file %s is a regular file\n", a, b)
+		return exitDiffer
+	case bInfo.IsDir():
+		fmt.Fprintf(d.out, "File %s is a regular file while file %s is a directory\n", a, b)
+		return exitDiffer
+	}
+	return d.compareFiles(a, b, header)
+}
+
+// compareDirs compares the entries of the directories a and b.
+func (d *differ) compareDirs(a, b string) int {
+	aEntries, err := ioutil.ReadDir(a)
+	if err != nil {
+		return d.trouble(err)
+	}
+	bEntries, err := ioutil.ReadDir(b)
+	if err != nil {
+		return d.trouble(err)
+	}
+
+	status := exitSame
+	worst := func(s int) {
+		if s > status {
+			status = s
+		}
+	}
+
+	// Both listings are sorted by name, so merge them.
+	for len(aEntries) > 0 || len(bEntries) > 0 {
+		switch {
+		case len(bEntries) == 0 || len(aEntries) > 0 && aEntries[0].Name() < bEntries[0].Name():
+			fmt.Fprintf(d.out, "Only in %s: %s\n", a, aEntries[0].Name())
+			aEntries = aEntries[1:]
+			worst(exitDiffer)
+		case len(aEntries) == 0 || bEntries[0].Name() < aEntries[0].Name():
+			fmt.Fprintf(d.out, "Only in %s: %s\n", b, bEntries[0].Name())
+			bEntries = bEntries[1:]
+			worst(exitDiffer)
+		default:
+			name := aEntries[0].Name()
+			aPath, bPath := filepath.Join(a, name), filepath.Join(b, name)
+			if aEntries[0].IsDir() && bEntries[0].IsDir() && !d.recursive {
+				fmt.Fprintf(d.out, "Common subdirectories: %s and %s\n", aPath, bPath)
+			} else {
+				worst(d.compare(aPath, bPath, false, true))
+			}
+			aEntries, bEntries = aEntries[1:], bEntries[1:]
+		}
+	}
+	return status
+}
+
+// A file is the contents of one side of the comparison.
+type file struct {
+	name  string
+	lines []string
+	noEOL bool // the last line is not terminated by a newline
+}
+
+func readFile(name string) (*file, []byte, error) {
+	raw, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	f := &file{name: name}
+	if len(raw) > 0 {
+		text := string(raw)
+		if strings.HasSuffix(text, "\n") {
+			text = text[:len(text)-1]
+		} else {
+			f.noEOL = true
+		}
+		f.lines = strings.Split(text, "\n")
+	}
+	return f, raw, nil
+}
+
+// isBinary reports whether data looks like the contents of a binary file.
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// defaultSection finds hunk headings in files other than Go source.
+var defaultSection = diff.RegexpSection(regexp.MustCompile(`^[[:alpha:]$_]`))
+
+// compareFiles compares the regular files a and b.  If header is true, a line
+// naming the files is written before any differences.
+func (d *differ) compareFiles(a, b string, header bool) int {
+	aFile, aRaw, err := readFile(a)
+	if err != nil {
+		return d.trouble(err)
+	}
+	bFile, bRaw, err := readFile(b)
+	if err != nil {
+		return d.trouble(err)
+	}
+
+	if isBinary(aRaw) || isBinary(bRaw) {
+		if bytes.Equal(aRaw, bRaw) {
+			return exitSame
+		}
+		fmt.Fprintf(d.out, "Binary files %s and %s differ\n", a, b)
+		return exitDiffer
+	}
+
+	chunks := diff.DiffChunks(d.keys(aFile), d.keys(bFile))
+	if d.cleanup {
+		chunks = diff.Cleanup(chunks)
+	}
+	lines := diff.Lines(chunks)
+
+	// The lines were compared using their keys, so restore the original text.
+	for i := range lines {
+		if l := &lines[i]; l.A >= 0 {
+			l.Text = aFile.lines[l.A]
+		} else {
+			l.Text = bFile.lines[l.B]
+		}
+	}
+	if d.moves {
+		diff.DetectMoves(lines, 3)
+	}
+	if d.words || d.color {
+		diff.Refine(lines)
+	}
+
+	var section diff.SectionFunc
+	if d.showFunc {
+		section = defaultSection
+		if filepath.Ext(a) == ".go" {
+			section = diff.GoSection
+		}
+	}
+	hunks := diff.Hunks(lines, d.context, section)
+	if d.ignoreBlankLines {
+		hunks = withoutBlankChanges(hunks)
+	}
+	if len(hunks) == 0 {
+		return exitSame
+	}
+
+	w := &writer{Writer: d.out, color: d.color, a: aFile, b: bFile}
+	if header {
+		w.line(colorHeader, "diff %s %s", a, b)
+	}
+	if d.sideBySide {
+		if d.ignoreBlankLines {
+			lines = unchangedOutside(lines, hunks)
+		}
+		w.sideBySide(lines, d.width)
+		return exitDiffer
+	}
+	w.line(colorHeader, "--- %s", a)
+	w.line(colorHeader, "+++ %s", b)
+	if d.words {
+		w.wordHunks(hunks)
+	} else {
+		w.unifiedHunks(hunks)
+	}
+	return exitDiffer
+}
+
+// keys returns the lines of f as they should be compared, according to the
+// options for which differences to ignore.
+func (d *differ) keys(f *file) []string {
+	keys := make([]string, len(f.lines))
+	for i, line := range f.lines {
+		if d.ignoreCase {
+			line = strings.ToLower(line)
+		}
+		switch {
+		case d.ignoreAllSpace:
+			line = strings.Map(func(r rune) rune {
+				if unicode.IsSpace(r) {
+					return -1
+				}
+				return r
+			}, line)
+		case d.ignoreSpaceChange:
+			line = strings.TrimRightFunc(line, unicode.IsSpace)
+			fields := strings.Fields(line)
+			if len(line) > 0 && unicode.IsSpace(rune(line[0])) {
+				fields = append([]string{""}, fields...)
+			}
+			line = strings.Join(fields, " ")
+		}
+		keys[i] = line
+	}
+	if n := len(keys); n > 0 && f.noEOL {
+		// A missing newline at the end of the file is a difference.
+		keys[n-1] += "\x00"
+	}
+	return keys
+}
+
+// withoutBlankChanges removes the hunks in which every changed line is blank.
+// The changes it removes are not shown in the side-by-side output either; see
+// unchangedOutside.
+func withoutBlankChanges(hunks []diff.Hunk) []diff.Hunk {
+	var out []diff.Hunk
+	for _, h := range hunks {
+		for _, l := range h.Lines {
+			if l.Op != diff.Equal && strings.TrimSpace(l.Text) != "" {
+				out = append(out, h)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// unchangedOutside returns a copy of lines in which the changes that are not
+// part of any of the hunks are marked as equal, for the side-by-side output,
+// which shows every line.
+func unchangedOutside(lines []diff.Line, hunks []diff.Hunk) []diff.Line {
+	type index struct{ a, b int }
+	kept := make(map[index]bool)
+	for _, h := range hunks {
+		for _, l := range h.Lines {
+			kept[index{l.A, l.B}] = true
+		}
+	}
+	out := append([]diff.Line(nil), lines...)
+	for i := range out {
+		if l := &out[i]; l.Op != diff.Equal && !kept[index{l.A, l.B}] {
+			l.Op = diff.Equal
+		}
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command godiff compares files line by line using the
+// github.com/kylelemons/godebug/diff package.
+//
+// Usage:
+//
+//	godiff [flags] FILE1 FILE2
+//
+// If both arguments are directories and -r is given, the files within them
+// are compared recursively.
+//
+// The output format is unified by default; -y selects side-by-side output and
+// -word selects a word diff, which shows changes within lines as [-old-] and
+// {+new+}.  Flags -b, -w, -B, and -i control which differences are ignored,
+// as in GNU diff.
+//
+// The exit status is 0 if the inputs are the same, 1 if they differ, and 2 if
+// there was trouble.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// options controls how files are compared and how differences are shown.
+type options struct {
+	// Output format
+	context    int
+	sideBySide bool
+	width      int
+	words      bool
+	color      bool
+	showFunc   bool
+	moves      bool
+	cleanup    bool
+
+	// Differences to ignore
+	ignoreSpaceChange bool
+	ignoreAllSpace    bool
+	ignoreBlankLines  bool
+	ignoreCase        bool
+
+	recursive bool
+}
+
+// Exit statuses, compatible with GNU diff.
+const (
+	exitSame    = 0
+	exitDiffer  = 1
+	exitTrouble = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs godiff with the given command-line arguments and returns its exit
+// status.
+func run(args []string, stdout, stderr io.Writer) int {
+	var opts options
+	var color string
+
+	fs := flag.NewFlagSet("godiff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: godiff [flags] FILE1 FILE2\n")
+		fs.PrintDefaults()
+	}
+	fs.Bool("u", true, "output in unified format (the default)")
+	fs.IntVar(&opts.context, "U", 3, "show `NUM` lines of unified context")
+	fs.BoolVar(&opts.sideBySide, "y", false, "output in two columns")
+	fs.IntVar(&opts.width, "W", 130, "output at most `NUM` columns with -y")
+	fs.BoolVar(&opts.words, "word", false, "show changes within lines as [-old-]{+new+}")
+	fs.StringVar(&color, "color", "auto", "colorize the output: `WHEN` is always, never, or auto")
+	fs.BoolVar(&opts.showFunc, "p", false, "show which function (or other section) each change is in")
+	fs.BoolVar(&opts.moves, "moved", false, "show blocks of lines which were moved")
+	fs.BoolVar(&opts.cleanup, "cleanup", false, "make changes more readable at the expense of minimality")
+	fs.BoolVar(&opts.ignoreSpaceChange, "b", false, "ignore changes in the amount of white space")
+	fs.BoolVar(&opts.ignoreAllSpace, "w", false, "ignore all white space")
+	fs.BoolVar(&opts.ignoreBlankLines, "B", false, "ignore changes whose lines are all blank")
+	fs.BoolVar(&opts.ignoreCase, "i", false, "ignore case differences")
+	fs.BoolVar(&opts.recursive, "r", false, "recursively compare subdirectories")
+	if err := fs.Parse(args); err != nil {
+		return exitTrouble
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return exitTrouble
+	}
+
+	switch color {
+	case "always":
+		opts.color = true
+	case "never":
+		opts.color = false
+	case "auto":
+		opts.color = isTerminal(stdout)
+	default:
+		fmt.Fprintf(stderr, "godiff: invalid -color %q\n", color)
+		return exitTrouble
+	}
+
+	d := &differ{options: opts, out: stdout, errs: stderr}
+	return d.compare(fs.Arg(0), fs.Arg(1), true, false)
+}
+
+// isTerminal reports whether w is a terminal which colored output can be
+// written to.  The NO_COLOR environment variable disables color.
+func isTerminal(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,341 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setup creates the given files (relative to a new temporary directory) and
+// returns the directory.
+func setup(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "godiff")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll: %s", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("WriteFile: %s", err)
+		}
+	}
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	dir := setup(t, map[string]string{
+		"a/text":      "one\ntwo\nthree\n",
+		"b/text":      "one\n2\nthree\n",
+		"a/spaces":    "if x {\n\treturn  y\n}\n",
+		"b/spaces":    "if x {\n    return y\n}\n",
+		"a/blank":     "one\ntwo\n",
+		"b/blank":     "one\n\ntwo\n",
+		"a/mixed":     "one\ntwo\nthree\nfour\n",
+		"b/mixed":     "one\n\ntwo\nthree\n4\n",
+		"a/case":      "Hello\n",
+		"b/case":      "hello\n",
+		"a/eol":       "last\n",
+		"b/eol":       "last",
+		"a/bin":       "\x00\x01",
+		"b/bin":       "\x00\x02",
+		"a/sub/same":  "same\n",
+		"b/sub/same":  "same\n",
+		"a/sub/other": "a\n",
+		"b/sub/other": "b\n",
+		"a/only":      "only\n",
+	})
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		desc   string
+		args   []string
+		status int
+		out    string
+	}{
+		{
+			desc:   "same",
+			args:   []string{"a/text", "a/text"},
+			status: exitSame,
+		},
+		{
+			desc:   "unified",
+			args:   []string{"-U", "1", "a/text", "b/text"},
+			status: exitDiffer,
+			out: `
+--- a/text
++++ b/text
+@@ -1,3 +1,3 @@
+ one
+-two
++2
+ three
+`,
+		},
+		{
+			desc:   "word",
+			args:   []string{"-word", "-U", "0", "a/text", "b/text"},
+			status: exitDiffer,
+			out: `
+--- a/text
++++ b/text
+@@ -2 +2 @@
+[-two-]{+2+}
+`,
+		},
+		{
+			desc:   "side by side",
+			args:   []string{"-y", "-W", "23", "a/text", "b/text"},
+			status: exitDiffer,
+			out: `
+one          one
+two        | 2
+three        three
+`,
+		},
+		{
+			desc:   "all space differs",
+			args:   []string{"a/spaces", "b/spaces"},
+			status: exitDiffer,
+			out: `
+--- a/spaces
++++ b/spaces
+@@ -1,3 +1,3 @@
+ if x {
+-	return  y
++    return y
+ }
+`,
+		},
+		{
+			desc:   "ignore space change",
+			args:   []string{"-b", "a/spaces", "b/spaces"},
+			status: exitSame,
+		},
+		{
+			desc:   "ignore all space",
+			args:   []string{"-w", "a/spaces", "b/spaces"},
+			status: exitSame,
+		},
+		{
+			desc:   "ignore blank lines",
+			args:   []string{"-B", "a/blank", "b/blank"},
+			status: exitSame,
+		},
+		{
+			desc:   "ignore blank lines side by side",
+			args:   []string{"-y", "-B", "-U", "0", "-W", "23", "a/mixed", "b/mixed"},
+			status: exitDiffer,
+			out: `
+one          one
+
+two          two
+three        three
+four       | 4
+`,
+		},
+		{
+			desc:   "ignore case",
+			args:   []string{"-i", "a/case", "b/case"},
+			status: exitSame,
+		},
+		{
+			desc:   "no newline at end of file",
+			args:   []string{"a/eol", "b/eol"},
+			status: exitDiffer,
+			out: `
+--- a/eol
++++ b/eol
+@@ -1 +1 @@
+-last
++last
+\ No newline at end of file
+`,
+		},
+		{
+			desc:   "no newline at end of file word",
+			args:   []string{"-word", "a/eol", "b/eol"},
+			status: exitDiffer,
+			out: `
+--- a/eol
++++ b/eol
+@@ -1 +1 @@
+last
+\ No newline at end of file
+`,
+		},
+		{
+			desc:   "binary",
+			args:   []string{"a/bin", "b/bin"},
+			status: exitDiffer,
+			out: `
+Binary files a/bin and b/bin differ
+`,
+		},
+		{
+			desc:   "directories",
+			args:   []string{"a/sub", "b/sub"},
+			status: exitDiffer,
+			out: `
+diff a/sub/other b/sub/other
+--- a/sub/other
++++ b/sub/other
+@@ -1 +1 @@
+-a
++b
+`,
+		},
+		{
+			desc:   "file and directory",
+			args:   []string{"-U", "1", "a/text", "b"},
+			status: exitDiffer,
+			out: `
+--- a/text
++++ b/text
+@@ -1,3 +1,3 @@
+ one
+-two
++2
+ three
+`,
+		},
+		{
+			desc:   "directory and file",
+			args:   []string{"-U", "1", "a", "b/text"},
+			status: exitDiffer,
+			out: `
+--- a/text
++++ b/text
+@@ -1,3 +1,3 @@
+ one
+-two
++2
+ three
+`,
+		},
+		{
+			desc:   "not recursive",
+			args:   []string{"a", "b"},
+			status: exitDiffer,
+		},
+		{
+			desc:   "recursive",
+			args:   []string{"-r", "-B", "-b", "-i", "a", "b"},
+			status: exitDiffer,
+			out: `
+Binary files a/bin and b/bin differ
+diff a/eol b/eol
+--- a/eol
++++ b/eol
+@@ -1 +1 @@
+-last
++last
+\ No newline at end of file
+diff a/mixed b/mixed
+--- a/mixed
++++ b/mixed
+@@ -1,4 +1,5 @@
+ one
++
+ two
+ three
+-four
++4
+Only in a: only
+diff a/sub/other b/sub/other
+--- a/sub/other
++++ b/sub/other
+@@ -1 +1 @@
+-a
++b
+diff a/text b/text
+--- a/text
++++ b/text
+@@ -1,3 +1,3 @@
+ one
+-two
++2
+ three
+`,
+		},
+		{
+			desc:   "missing",
+			args:   []string{"a/missing", "b/text"},
+			status: exitTrouble,
+		},
+		{
+			desc:   "bad flag",
+			args:   []string{"-color=sometimes", "a/text", "b/text"},
+			status: exitTrouble,
+		},
+		{
+			desc:   "wrong number of arguments",
+			args:   []string{"a/text"},
+			status: exitTrouble,
+		},
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %s", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %s", err)
+	}
+	defer os.Chdir(wd)
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			status := run(append([]string{"-color=never"}, test.args...), stdout, stderr)
+			if got, want := status, test.status; got != want {
+				t.Errorf("godiff %s: exit status %d, want %d (stderr: %q)", strings.Join(test.args, " "), got, want, stderr)
+			}
+			if test.out == "" {
+				return
+			}
+			if got, want := stdout.String(), strings.TrimPrefix(test.out, "\n"); got != want {
+				t.Errorf("godiff %s:\nGOT\n%s\nWANT\n%s", strings.Join(test.args, " "), got, want)
+			}
+		})
+	}
+}
+
+func TestMergeWords(t *testing.T) {
+	dir := setup(t, map[string]string{
+		"a": "f(a, b)\nthe quick fox\n",
+		"b": "f(a, b, c)\nthe slow fox\n",
+	})
+	defer os.RemoveAll(dir)
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	run([]string{"-word", "-U", "0", filepath.Join(dir, "a"), filepath.Join(dir, "b")}, stdout, stderr)
+
+	for _, want := range []string{
+		"f(a, b{+, c+})\n",
+		"the [-quick-]{+slow+} fox\n",
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, stdout)
+		}
+	}
+}
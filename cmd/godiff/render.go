@@ -0,0 +1,301 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kylelemons/godebug/diff"
+)
+
+// ANSI escape sequences for colored output.  Moved lines use the same colors
+// as git's --color-moved.
+const (
+	colorReset     = "\x1b[m"
+	colorHeader    = "\x1b[1m"
+	colorHunk      = "\x1b[36m"
+	colorDeleted   = "\x1b[31m"
+	colorAdded     = "\x1b[32m"
+	colorMovedFrom = "\x1b[1;35m"
+	colorMovedTo   = "\x1b[1;36m"
+	colorSpanOn    = "\x1b[7m"
+	colorSpanOff   = "\x1b[27m"
+)
+
+// writer writes the differences between two files.
+type writer struct {
+	io.Writer
+	color bool
+	a, b  *file
+}
+
+// line writes a line of output in the given color.
+func (w *writer) line(color, format string, args ...interface{}) {
+	text := fmt.Sprintf(format, args...)
+	if w.color && color != "" {
+		text = color + text + colorReset
+	}
+	fmt.Fprintln(w, text)
+}
+
+// colorOf returns the color for a line of the edit script.
+func colorOf(l diff.Line) string {
+	switch {
+	case l.Op == diff.Deleted && l.Move != 0:
+		return colorMovedFrom
+	case l.Op == diff.Added && l.Move != 0:
+		return colorMovedTo
+	case l.Op == diff.Deleted:
+		return colorDeleted
+	case l.Op == diff.Added:
+		return colorAdded
+	}
+	return ""
+}
+
+// prefixOf returns the unified diff prefix for a line.  Moved lines are shown
+// with color when it is enabled, and with distinct prefixes otherwise.
+func (w *writer) prefixOf(l diff.Line) string {
+	switch {
+	case l.Op == diff.Deleted && l.Move != 0 && !w.color:
+		return "<"
+	case l.Op == diff.Added && l.Move != 0 && !w.color:
+		return ">"
+	case l.Op == diff.Deleted:
+		return "-"
+	case l.Op == diff.Added:
+		return "+"
+	}
+	return " "
+}
+
+// highlight returns the text of l with its spans highlighted, if color is on.
+func (w *writer) highlight(l diff.Line) string {
+	if !w.color || len(l.Spans) == 0 || l.Move != 0 {
+		return l.Text
+	}
+	var sb strings.Builder
+	last := 0
+	for _, s := range l.Spans {
+		sb.WriteString(l.Text[last:s.Start])
+		sb.WriteString(colorSpanOn)
+		sb.WriteString(l.Text[s.Start:s.End])
+		sb.WriteString(colorSpanOff)
+		last = s.End
+	}
+	sb.WriteString(l.Text[last:])
+	return sb.String()
+}
+
+// noEOL reports whether l is the last line of a file which does not end in a
+// newline.
+func (w *writer) noEOL(l diff.Line) bool {
+	if l.Op == diff.Added {
+		return w.b.noEOL && l.B == len(w.b.lines)-1
+	}
+	return w.a.noEOL && l.A == len(w.a.lines)-1
+}
+
+// header writes the header line of a hunk.
+func (w *writer) header(h diff.Hunk) {
+	h.Lines = nil
+	w.line(colorHunk, "%s", diff.RenderHunks([]diff.Hunk{h}))
+}
+
+// unifiedHunks writes the hunks in unified format.
+func (w *writer) unifiedHunks(hunks []diff.Hunk) {
+	for _, h := range hunks {
+		w.header(h)
+		for _, l := range h.Lines {
+			w.line(colorOf(l), "%s%s", w.prefixOf(l), w.highlight(l))
+			if w.noEOL(l) {
+				w.line("", `\ No newline at end of file`)
+			}
+		}
+	}
+}
+
+// pairs calls fn for each line of the script.  Runs of deleted lines followed
+// by runs of added lines are paired up, in which case fn is called with both;
+// otherwise one of del and add is nil.
+func pairs(lines []diff.Line, fn func(del, add *diff.Line)) {
+	for i := 0; i < len(lines); {
+		switch lines[i].Op {
+		case diff.Equal:
+			fn(&lines[i], &lines[i])
+			i++
+			continue
+		case diff.Added:
+			fn(nil, &lines[i])
+			i++
+			continue
+		}
+
+		del := i
+		for i < len(lines) && lines[i].Op == diff.Deleted {
+			i++
+		}
+		add := i
+		for i < len(lines) && lines[i].Op == diff.Added {
+			i++
+		}
+		for k := 0; del+k < add || add+k < i; k++ {
+			var d, a *diff.Line
+			if del+k < add {
+				d = &lines[del+k]
+			}
+			if add+k < i {
+				a = &lines[add+k]
+			}
+			fn(d, a)
+		}
+	}
+}
+
+// wordHunks writes the hunks as a word diff.
+func (w *writer) wordHunks(hunks []diff.Hunk) {
+	deleted := func(s string) string {
+		if w.color {
+			return colorDeleted + s + colorReset
+		}
+		return "[-" + s + "-]"
+	}
+	added := func(s string) string {
+		if w.color {
+			return colorAdded + s + colorReset
+		}
+		return "{+" + s + "+}"
+	}
+
+	for _, h := range hunks {
+		w.header(h)
+		pairs(h.Lines, func(del, add *diff.Line) {
+			switch {
+			case del == add:
+				w.line("", "%s", del.Text)
+			case add == nil:
+				w.line("", "%s", deleted(del.Text))
+			case del == nil:
+				w.line("", "%s", added(add.Text))
+			default:
+				w.line("", "%s", mergeWords(*del, *add, deleted, added))
+			}
+			if del != nil && w.noEOL(*del) || add != nil && w.noEOL(*add) {
+				w.line("", `\ No newline at end of file`)
+			}
+		})
+	}
+}
+
+// mergeWords combines a deleted line and the added line which replaced it
+// into a single line, using the functions to mark their differing spans.
+func mergeWords(del, add diff.Line, deleted, added func(string) string) string {
+	var sb strings.Builder
+	dSpans, aSpans := del.Spans, add.Spans
+	d, a := 0, 0
+	for d < len(del.Text) || a < len(add.Text) {
+		switch {
+		case len(dSpans) > 0 && dSpans[0].Start == d:
+			sb.WriteString(deleted(del.Text[d:dSpans[0].End]))
+			d, dSpans = dSpans[0].End, dSpans[1:]
+		case len(aSpans) > 0 && aSpans[0].Start == a:
+			sb.WriteString(added(add.Text[a:aSpans[0].End]))
+			a, aSpans = aSpans[0].End, aSpans[1:]
+		case d >= len(del.Text):
+			sb.WriteString(added(add.Text[a:]))
+			a = len(add.Text)
+		case a >= len(add.Text):
+			sb.WriteString(deleted(del.Text[d:]))
+			d = len(del.Text)
+		default:
+			// Outside of the spans, the two lines are the same.
+			sb.WriteByte(del.Text[d])
+			d, a = d+1, a+1
+		}
+	}
+	return sb.String()
+}
+
+// sideBySide writes the entire edit script in two columns.
+func (w *writer) sideBySide(lines []diff.Line, width int) {
+	col := (width - 3) / 2
+	if col < 1 {
+		col = 1
+	}
+	pairs(lines, func(del, add *diff.Line) {
+		var left, right, gutter string
+		switch {
+		case del == add:
+			left, right, gutter = del.Text, add.Text, " "
+		case add == nil:
+			left, gutter = del.Text, "<"
+		case del == nil:
+			right, gutter = add.Text, ">"
+		default:
+			left, right, gutter = del.Text, add.Text, "|"
+		}
+		left, right = column(left, col), column(right, col)
+
+		if w.color {
+			if del != nil && del != add {
+				left = colorOf(*del) + left + colorReset
+			}
+			if add != nil && del != add {
+				right = colorOf(*add) + right + colorReset
+			}
+		}
+		text := left + strings.Repeat(" ", col-utf8.RuneCountInString(stripColor(left))) + " " + gutter + " " + right
+		w.line("", "%s", strings.TrimRight(text, " "))
+	})
+}
+
+// column expands tabs in s and truncates it to width runes.
+func column(s string, width int) string {
+	var sb strings.Builder
+	n := 0
+	for _, r := range s {
+		if r == '\t' {
+			for pad := 8 - n%8; pad > 0 && n < width; pad-- {
+				sb.WriteByte(' ')
+				n++
+			}
+			continue
+		}
+		if n >= width {
+			break
+		}
+		sb.WriteRune(r)
+		n++
+	}
+	return sb.String()
+}
+
+// stripColor removes the ANSI escape sequences used by writer from s.
+func stripColor(s string) string {
+	for {
+		start := strings.Index(s, "\x1b[")
+		if start < 0 {
+			return s
+		}
+		end := strings.IndexByte(s[start:], 'm')
+		if end < 0 {
+			return s
+		}
+		s = s[:start] + s[start+end+1:]
+	}
+}
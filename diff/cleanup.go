@@ -0,0 +1,351 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"strings"
+)
+
+// Cleanup post-processes the chunks returned by DiffChunks to make them easier
+// for a human to read.  The result describes the same edits, but is no longer
+// necessarily minimal.
+//
+// Two passes are performed:
+//
+// First, blocks of purely added or purely deleted lines are slid up or down
+// (when the lines around them allow it) to the position that the indent
+// heuristic from git considers most natural, so that a block tends to start
+// and end on the boundaries of indented regions and blank lines.
+//
+// Second, short coincidental runs of equal lines (such as blank lines or a
+// lone closing brace) which separate two larger changes are folded into the
+// surrounding changes, as in the semantic cleanup from diff-match-patch.  The
+// length of a run of lines is measured in non-whitespace characters.
+//
+// The input chunks are not modified.
+func Cleanup(chunks []Chunk) []Chunk {
+	chunks = normalize(chunks)
+	slide(chunks)
+	chunks = normalize(chunks)
+	chunks = mergeSemantic(chunks)
+
+	// Split the chunks back up so that deletions come before additions.
+	var out []Chunk
+	for _, c := range chunks {
+		if len(c.Added) > 0 && len(c.Deleted) > 0 {
+			out = append(out, Chunk{Deleted: c.Deleted})
+			c.Deleted = nil
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// normalize returns a copy of chunks in which every chunk except the last has
+// equal lines and every chunk except the first has added or deleted lines.
+func normalize(chunks []Chunk) []Chunk {
+	var out []Chunk
+	for _, c := range chunks {
+		if c.empty() {
+			continue
+		}
+		if n := len(out); n > 0 && len(out[n-1].Equal) == 0 {
+			last := &out[n-1]
+			last.Added = join(last.Added, c.Added)
+			last.Deleted = join(last.Deleted, c.Deleted)
+			last.Equal = join(nil, c.Equal)
+			continue
+		}
+		if n := len(out); n > 0 && len(c.Added) == 0 && len(c.Deleted) == 0 {
+			last := &out[n-1]
+			last.Equal = join(last.Equal, c.Equal)
+			continue
+		}
+		out = append(out, Chunk{
+			Added:   join(nil, c.Added),
+			Deleted: join(nil, c.Deleted),
+			Equal:   join(nil, c.Equal),
+		})
+	}
+	return out
+}
+
+// join returns a new slice containing the lines of a followed by those of b.
+func join(a, b []string) []string {
+	if len(a)+len(b) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(a)+len(b))
+	return append(append(out, a...), b...)
+}
+
+// slide moves each purely added or purely deleted block of normalized chunks
+// to its best position according to the indent heuristic.
+func slide(chunks []Chunk) {
+	// The full text of each side, for measuring the context of a split.
+	var aFile, bFile []string
+	aStart := make([]int, len(chunks))
+	bStart := make([]int, len(chunks))
+	for i, c := range chunks {
+		aStart[i], bStart[i] = len(aFile), len(bFile)
+		aFile = append(append(aFile, c.Deleted...), c.Equal...)
+		bFile = append(append(bFile, c.Added...), c.Equal...)
+	}
+
+	for i := 1; i < len(chunks); i++ {
+		prev, cur := &chunks[i-1], &chunks[i]
+
+		var block *[]string
+		var file []string
+		var start int
+		switch {
+		case len(cur.Added) > 0 && len(cur.Deleted) == 0:
+			block, file, start = &cur.Added, bFile, bStart[i]
+		case len(cur.Deleted) > 0 && len(cur.Added) == 0:
+			block, file, start = &cur.Deleted, aFile, aStart[i]
+		default:
+			continue
+		}
+
+		// Slide the block as far up as it will go, then try every position on
+		// the way back down.
+		size := len(*block)
+		top := start
+		for top > start-len(prev.Equal) && file[top-1] == file[top+size-1] {
+			top--
+		}
+		best, bestScore := -1, splitScore{}
+		for pos := top; pos <= start+len(cur.Equal); pos++ {
+			if pos > top && file[pos-1] != file[pos+size-1] {
+				break
+			}
+			var score splitScore
+			score.add(measureSplit(file, pos))
+			score.add(measureSplit(file, pos+size))
+			if best == -1 || score.cmp(bestScore) <= 0 {
+				best, bestScore = pos, score
+			}
+		}
+		if best == start {
+			continue
+		}
+
+		// Rebuild the surrounding equal lines for the new position.  The file
+		// contents are the same either way, since the lines that move across
+		// the block are identical.
+		shift := best - start
+		eqEnd := start + size + len(cur.Equal)
+		prev.Equal = join(nil, file[start-len(prev.Equal):best])
+		*block = join(nil, file[best:best+size])
+		cur.Equal = join(nil, file[best+size:eqEnd])
+		aStart[i] += shift
+		bStart[i] += shift
+	}
+}
+
+// Parameters for the indent heuristic, from git's xdiff/xdiffi.c.
+const (
+	maxIndent = 200
+	maxBlanks = 20
+
+	startOfFilePenalty             = 1
+	endOfFilePenalty               = 21
+	totalBlankWeight               = -30
+	postBlankWeight                = 6
+	relativeIndentPenalty          = -4
+	relativeIndentWithBlankPenalty = 10
+	relativeOutdentPenalty         = 24
+	relativeOutdentWithBlank       = 17
+	relativeDedentPenalty          = 23
+	relativeDedentWithBlank        = 17
+	indentWeight                   = 60
+)
+
+// indentOf returns the indentation of line in columns, or -1 if it is blank.
+func indentOf(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 8 - n%8
+		case '\r', '\f', '\v':
+		default:
+			return n
+		}
+		if n >= maxIndent {
+			return maxIndent
+		}
+	}
+	return -1
+}
+
+// splitMeasure describes the lines around a split between two lines.
+type splitMeasure struct {
+	endOfFile  bool
+	indent     int // indent of the line after the split, -1 if blank
+	preBlank   int // blank lines before the split
+	preIndent  int // indent of the first non-blank line before the split
+	postBlank  int // blank lines after the line after the split
+	postIndent int // indent of the next non-blank line after that
+}
+
+// measureSplit measures the split of file between lines split-1 and split.
+func measureSplit(file []string, split int) splitMeasure {
+	m := splitMeasure{indent: -1, preIndent: -1, postIndent: -1}
+	if split >= len(file) {
+		m.endOfFile = true
+	} else {
+		m.indent = indentOf(file[split])
+	}
+
+	for i := split - 1; i >= 0; i-- {
+		if indent := indentOf(file[i]); indent != -1 {
+			m.preIndent = indent
+			break
+		}
+		if m.preBlank++; m.preBlank == maxBlanks {
+			m.preIndent = 0
+			break
+		}
+	}
+
+	for i := split + 1; i < len(file); i++ {
+		if indent := indentOf(file[i]); indent != -1 {
+			m.postIndent = indent
+			break
+		}
+		if m.postBlank++; m.postBlank == maxBlanks {
+			m.postIndent = 0
+			break
+		}
+	}
+	return m
+}
+
+// splitScore accumulates the score of the splits around a block.
+// Lower scores are better.
+type splitScore struct {
+	effectiveIndent int
+	penalty         int
+}
+
+func (s *splitScore) add(m splitMeasure) {
+	if m.preIndent == -1 && m.preBlank == 0 {
+		s.penalty += startOfFilePenalty
+	}
+	if m.endOfFile {
+		s.penalty += endOfFilePenalty
+	}
+
+	postBlank := 0
+	if m.indent == -1 {
+		postBlank = 1 + m.postBlank
+	}
+	totalBlank := m.preBlank + postBlank
+	s.penalty += totalBlankWeight * totalBlank
+	s.penalty += postBlankWeight * postBlank
+
+	indent := m.indent
+	if indent == -1 {
+		indent = m.postIndent
+	}
+	anyBlanks := totalBlank != 0
+	s.effectiveIndent += indent
+
+	switch {
+	case indent == -1, m.preIndent == -1, indent == m.preIndent:
+		// No adjustment
+	case indent > m.preIndent:
+		if anyBlanks {
+			s.penalty += relativeIndentWithBlankPenalty
+		} else {
+			s.penalty += relativeIndentPenalty
+		}
+	case m.postIndent != -1 && m.postIndent > indent:
+		if anyBlanks {
+			s.penalty += relativeOutdentWithBlank
+		} else {
+			s.penalty += relativeOutdentPenalty
+		}
+	default:
+		if anyBlanks {
+			s.penalty += relativeDedentWithBlank
+		} else {
+			s.penalty += relativeDedentPenalty
+		}
+	}
+}
+
+// cmp returns a negative number if s is better than o, zero if they are
+// equivalent, and a positive number if s is worse.
+func (s splitScore) cmp(o splitScore) int {
+	cmpIndents := 0
+	switch {
+	case s.effectiveIndent > o.effectiveIndent:
+		cmpIndents = 1
+	case s.effectiveIndent < o.effectiveIndent:
+		cmpIndents = -1
+	}
+	return indentWeight*cmpIndents + (s.penalty - o.penalty)
+}
+
+// mergeSemantic folds short runs of equal lines between two changes into the
+// changes on either side of them.
+func mergeSemantic(chunks []Chunk) []Chunk {
+	for i := 0; i+1 < len(chunks); {
+		cur, next := chunks[i], chunks[i+1]
+		if len(cur.Added)+len(cur.Deleted) == 0 {
+			i++
+			continue
+		}
+		eq := weight(cur.Equal)
+		if eq > maxInt(weight(cur.Added), weight(cur.Deleted)) || eq > maxInt(weight(next.Added), weight(next.Deleted)) {
+			i++
+			continue
+		}
+
+		chunks[i] = Chunk{
+			Added:   join(join(cur.Added, cur.Equal), next.Added),
+			Deleted: join(join(cur.Deleted, cur.Equal), next.Deleted),
+			Equal:   next.Equal,
+		}
+		chunks = append(chunks[:i+1], chunks[i+2:]...)
+
+		// The merged change is larger, so the equality before it may now be
+		// eligible for merging as well.
+		if i > 0 {
+			i--
+		}
+	}
+	return chunks
+}
+
+// weight returns the number of non-whitespace characters in lines.
+func weight(lines []string) int {
+	n := 0
+	for _, line := range lines {
+		n += len(strings.Join(strings.Fields(line), ""))
+	}
+	return n
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
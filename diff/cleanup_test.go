@@ -0,0 +1,149 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCleanup(t *testing.T) {
+	tests := []struct {
+		desc   string
+		chunks []Chunk
+		out    string
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc: "unchanged",
+			chunks: []Chunk{
+				{Equal: []string{"a", "b"}},
+			},
+			out: strings.Trim(`
+ a
+ b
+			`, "\n\t"),
+		},
+		{
+			desc: "slide to function boundary",
+			chunks: []Chunk{
+				{Equal: []string{"func a() {", "\tone()"}},
+				{Added: []string{"}", "", "func b() {", "\ttwo()"}, Equal: []string{"}", "", "func c() {", "\tthree()", "}"}},
+			},
+			out: strings.Trim(`
+ func a() {
+ 	one()
+ }
+ 
++func b() {
++	two()
++}
++
+ func c() {
+ 	three()
+ }
+			`, "\n\t"),
+		},
+		{
+			desc: "slide deletion",
+			chunks: []Chunk{
+				{Equal: []string{"x", "{"}},
+				{Deleted: []string{"\ta", "}", "{"}, Equal: []string{"\ta", "}", "y"}},
+			},
+			out: strings.Trim(`
+ x
+ {
+ 	a
+ }
+-{
+-	a
+-}
+ y
+			`, "\n\t"),
+		},
+		{
+			desc: "merge coincidental braces",
+			chunks: []Chunk{
+				{Deleted: []string{"if x {", "\tone()"}},
+				{Added: []string{"for {", "\tthree()"}, Equal: []string{"}", ""}},
+				{Deleted: []string{"if y {", "\ttwo()"}},
+				{Added: []string{"for {", "\tfour()"}, Equal: []string{"}"}},
+			},
+			out: strings.TrimSpace(`
+-if x {
+-	one()
+-}
+-
+-if y {
+-	two()
++for {
++	three()
++}
++
++for {
++	four()
+ }
+			`),
+		},
+		{
+			desc: "keep significant equality",
+			chunks: []Chunk{
+				{Deleted: []string{"a"}},
+				{Added: []string{"b"}, Equal: []string{"return nil"}},
+				{Deleted: []string{"c"}},
+				{Added: []string{"d"}},
+			},
+			out: strings.TrimSpace(`
+-a
++b
+ return nil
+-c
++d
+			`),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if got, want := Render(Cleanup(test.chunks)), test.out; got != want {
+				t.Errorf("Cleanup(%q):", test.chunks)
+				t.Errorf("GOT\n%s", got)
+				t.Errorf("WANT\n%s", want)
+			}
+		})
+	}
+}
+
+func TestCleanupPreservesEdits(t *testing.T) {
+	a := strings.Split("a\n}\n\nb\n}\n\nc\n}\n\nd", "\n")
+	b := strings.Split("x\n}\n\nb\n}\n\ny\n}\n\nz", "\n")
+
+	var gotA, gotB []string
+	for _, c := range Cleanup(DiffChunks(a, b)) {
+		if len(c.Added) > 0 && len(c.Deleted) > 0 {
+			t.Errorf("chunk %q has both added and deleted lines", c)
+		}
+		gotA = append(append(gotA, c.Deleted...), c.Equal...)
+		gotB = append(append(gotB, c.Added...), c.Equal...)
+	}
+	if got, want := strings.Join(gotA, "\n"), strings.Join(a, "\n"); got != want {
+		t.Errorf("A = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(gotB, "\n"), strings.Join(b, "\n"); got != want {
+		t.Errorf("B = %q, want %q", got, want)
+	}
+}
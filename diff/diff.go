@@ -62,6 +62,100 @@ func Render(chunks []Chunk) string {
 	return strings.TrimRight(buf.String(), "\n")
 }
 
+// An Op describes what happened to a single Line of an edit script.
+type Op int
+
+// Ops in an edit script.
+const (
+	Equal   Op = iota // The line is the same in A and B.
+	Deleted           // The line is only in A.
+	Added             // The line is only in B.
+)
+
+var opNames = map[Op]string{
+	Equal:   "equal",
+	Deleted: "deleted",
+	Added:   "added",
+}
+
+// String returns the name of the Op.
+func (o Op) String() string {
+	if name, ok := opNames[o]; ok {
+		return name
+	}
+	return fmt.Sprintf("Op(%d)", int(o))
+}
+
+// A Line is a single line of an edit script.
+type Line struct {
+	Op   Op
+	Text string
+
+	// A and B are the zero-based indices of the line within A and B.
+	// The index for a side which does not contain the line is -1.
+	A, B int
+
+	// Move is nonzero if the line is part of a block of lines that was moved
+	// (see DetectMoves).  The deleted and the added lines of a moved block
+	// share the same Move number.
+	Move int
+
+	// Spans are the parts of Text which differ from the corresponding line on
+	// the other side, if they have been computed (see Refine).
+	Spans []Span
+}
+
+// Lines flattens the chunks into a line-by-line edit script.  Within a chunk,
+// deleted lines come before added lines, followed by the equal lines.
+func Lines(chunks []Chunk) []Line {
+	var lines []Line
+	var aidx, bidx int
+	for _, c := range chunks {
+		for _, text := range c.Deleted {
+			lines = append(lines, Line{Op: Deleted, Text: text, A: aidx, B: -1})
+			aidx++
+		}
+		for _, text := range c.Added {
+			lines = append(lines, Line{Op: Added, Text: text, A: -1, B: bidx})
+			bidx++
+		}
+		for _, text := range c.Equal {
+			lines = append(lines, Line{Op: Equal, Text: text, A: aidx, B: bidx})
+			aidx++
+			bidx++
+		}
+	}
+	return lines
+}
+
+// RenderLines renders the edit script with each line prefixed by '+', '-', or
+// ' ' like Render does.  Lines that are part of a moved block are rendered
+// distinctly: they are prefixed with '<' where the block was moved from and
+// with '>' where it was moved to.
+func RenderLines(lines []Line) string {
+	buf := new(strings.Builder)
+	for _, l := range lines {
+		buf.WriteByte(l.prefix())
+		buf.WriteString(l.Text)
+		buf.WriteByte('\n')
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+func (l Line) prefix() byte {
+	switch {
+	case l.Op == Deleted && l.Move != 0:
+		return '<'
+	case l.Op == Added && l.Move != 0:
+		return '>'
+	case l.Op == Deleted:
+		return '-'
+	case l.Op == Added:
+		return '+'
+	}
+	return ' '
+}
+
 // DiffChunks uses an O(D(N+M)) shortest-edit-script algorithm
 // to compute the edits required from A to B and returns the
 // edit chunks.
@@ -197,6 +197,25 @@ func TestRender(t *testing.T) {
 	}
 }
 
+func TestLines(t *testing.T) {
+	chunks := []Chunk{
+		{Equal: []string{"a"}},
+		{Deleted: []string{"b"}},
+		{Added: []string{"B"}, Equal: []string{"c"}},
+	}
+	want := []Line{
+		{Op: Equal, Text: "a", A: 0, B: 0},
+		{Op: Deleted, Text: "b", A: 1, B: -1},
+		{Op: Added, Text: "B", A: -1, B: 1},
+		{Op: Equal, Text: "c", A: 2, B: 2},
+	}
+	if got := Lines(chunks); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines(%q):", chunks)
+		t.Errorf("GOT  %+v", got)
+		t.Errorf("WANT %+v", want)
+	}
+}
+
 func ExampleDiff() {
 	constitution := strings.TrimSpace(`
 We the People of the United States, in Order to form a more perfect Union,
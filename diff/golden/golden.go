@@ -0,0 +1,107 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package golden compares test output against the contents of golden files.
+//
+// A typical test stores its expected output under testdata:
+//
+//	func TestRender(t *testing.T) {
+//		golden.Check(t, "testdata/render.golden", Render(input))
+//	}
+//
+// When the output changes intentionally, the golden files can be rewritten by
+// running the tests with GOLDEN_UPDATE=1 in the environment:
+//
+//	GOLDEN_UPDATE=1 go test ./...
+//
+// This package does not register any flags, but a test binary which defines
+// its own -update flag can use it to rewrite the golden files as well.
+package golden
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/diff"
+)
+
+// Context is the number of lines of context shown around each change.
+var Context = 3
+
+// UpdateEnv is the environment variable which, when set to a non-empty value
+// other than "0" or "false", causes golden files to be rewritten.
+const UpdateEnv = "GOLDEN_UPDATE"
+
+// Updating reports whether golden files should be rewritten instead of checked,
+// which is the case if UpdateEnv is set, or if the test binary defines an
+// -update flag of its own and it is true.
+func Updating() bool {
+	if f := flag.Lookup("update"); f != nil && f.Value.String() == "true" {
+		return true
+	}
+	switch os.Getenv(UpdateEnv) {
+	case "", "0", "false":
+		return false
+	}
+	return true
+}
+
+// Check compares got against the contents of the golden file at path and
+// reports any differences as a unified diff via t.Errorf.  Line endings are
+// normalized to "\n" in both before they are compared.
+//
+// If Updating returns true, the golden file (and any missing parent
+// directories) is instead written with the contents of got.
+func Check(t testing.TB, path, got string) {
+	t.Helper()
+	got = normalize(got)
+
+	if Updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("golden: creating directory for %s: %s", path, err)
+		}
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatalf("golden: updating %s: %s", path, err)
+		}
+		return
+	}
+
+	raw, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Errorf("golden: %s does not exist; run with "+UpdateEnv+"=1 to create it", path)
+		return
+	}
+	if err != nil {
+		t.Fatalf("golden: reading %s: %s", path, err)
+	}
+	want := normalize(string(raw))
+	if got == want {
+		return
+	}
+
+	chunks := diff.DiffChunks(strings.Split(want, "\n"), strings.Split(got, "\n"))
+	hunks := diff.Hunks(diff.Lines(chunks), Context, nil)
+	t.Errorf("golden: output differs from %s (-want +got):\n%s\nrun with "+UpdateEnv+"=1 to accept the new output",
+		path, diff.RenderHunks(hunks))
+}
+
+// normalize converts CRLF and CR line endings to LF.
+func normalize(s string) string {
+	s = strings.Replace(s, "\r\n", "\n", -1)
+	return strings.Replace(s, "\r", "\n", -1)
+}
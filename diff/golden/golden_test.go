@@ -0,0 +1,182 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golden
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recorder is a testing.TB which records the errors that are reported.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	panic(fmt.Sprintf(format, args...))
+}
+
+// update is defined as importers of this package would define it.
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "golden")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	return dir
+}
+
+func setUpdate(t *testing.T, value string) (restore func()) {
+	old, ok := os.LookupEnv(UpdateEnv)
+	os.Setenv(UpdateEnv, value)
+	return func() {
+		if ok {
+			os.Setenv(UpdateEnv, old)
+		} else {
+			os.Unsetenv(UpdateEnv)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	defer setUpdate(t, "")()
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.golden")
+	if err := ioutil.WriteFile(path, []byte("one\r\ntwo\r\nthree\r\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	tests := []struct {
+		desc string
+		path string
+		got  string
+		want []string // substrings of the single reported error
+	}{
+		{
+			desc: "same",
+			path: path,
+			got:  "one\ntwo\nthree\n",
+		},
+		{
+			desc: "line endings",
+			path: path,
+			got:  "one\rtwo\r\nthree\n",
+		},
+		{
+			desc: "different",
+			path: path,
+			got:  "one\n2\nthree\n",
+			want: []string{
+				"output differs from " + path,
+				"@@ -1,4 +1,4 @@\n one\n-two\n+2\n three\n \n",
+				UpdateEnv + "=1",
+			},
+		},
+		{
+			desc: "missing",
+			path: filepath.Join(dir, "missing.golden"),
+			got:  "anything",
+			want: []string{"missing.golden does not exist"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			r := &recorder{TB: t}
+			Check(r, test.path, test.got)
+
+			if len(test.want) == 0 {
+				if len(r.errors) > 0 {
+					t.Errorf("Check reported errors: %q", r.errors)
+				}
+				return
+			}
+			if len(r.errors) != 1 {
+				t.Fatalf("Check reported %d errors, want 1: %q", len(r.errors), r.errors)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(r.errors[0], want) {
+					t.Errorf("error %q does not contain %q", r.errors[0], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckUpdate(t *testing.T) {
+	defer setUpdate(t, "1")()
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "new", "dir", "test.golden")
+	r := &recorder{TB: t}
+	Check(r, path, "hello\r\nworld\n")
+	if len(r.errors) > 0 {
+		t.Errorf("Check reported errors: %q", r.errors)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+	if got, want := string(data), "hello\nworld\n"; got != want {
+		t.Errorf("golden file contains %q, want %q", got, want)
+	}
+}
+
+func TestUpdating(t *testing.T) {
+	tests := []struct {
+		env  string
+		want bool
+	}{
+		{"", false},
+		{"0", false},
+		{"false", false},
+		{"1", true},
+		{"true", true},
+	}
+
+	for _, test := range tests {
+		restore := setUpdate(t, test.env)
+		if got, want := Updating(), test.want; got != want {
+			t.Errorf("Updating() with %s=%q = %v, want %v", UpdateEnv, test.env, got, want)
+		}
+		restore()
+	}
+
+	defer setUpdate(t, "")()
+	*update = true
+	defer func() { *update = false }()
+	if !Updating() {
+		t.Errorf("Updating() with -update = false, want true")
+	}
+}
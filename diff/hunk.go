@@ -0,0 +1,139 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Hunk is a group of nearby changes in an edit script along with the equal
+// lines which surround them.
+type Hunk struct {
+	// AStart and BStart are the zero-based indices in A and B of the first
+	// line of the hunk.  ALen and BLen are the number of lines of A and B
+	// which the hunk covers.
+	AStart, ALen int
+	BStart, BLen int
+
+	// Section is the heading of the section of A in which the hunk starts, as
+	// determined by the SectionFunc passed to Hunks.
+	Section string
+
+	Lines []Line
+}
+
+// A SectionFunc returns the heading for a hunk which starts at lines[index],
+// such as the signature of the function that contains it.  It returns the
+// empty string if there is no suitable heading.
+type SectionFunc func(lines []string, index int) string
+
+// Hunks groups the changes in the edit script into hunks, each of which
+// includes up to context equal lines before and after its changes.  Changes
+// which are separated by no more than 2*context equal lines share a hunk.
+//
+// If section is non-nil, it is called to fill in the Section of each hunk from
+// the lines of A.
+func Hunks(lines []Line, context int, section SectionFunc) []Hunk {
+	if context < 0 {
+		context = 0
+	}
+
+	// Count the lines of A and B which precede each line of the script.
+	aPos := make([]int, len(lines)+1)
+	bPos := make([]int, len(lines)+1)
+	var aLines []string
+	for i, l := range lines {
+		aPos[i+1], bPos[i+1] = aPos[i], bPos[i]
+		if l.Op != Added {
+			aPos[i+1]++
+			aLines = append(aLines, l.Text)
+		}
+		if l.Op != Deleted {
+			bPos[i+1]++
+		}
+	}
+
+	var hunks []Hunk
+	for i := 0; i < len(lines); i++ {
+		if lines[i].Op == Equal {
+			continue
+		}
+
+		// Find the last change which belongs in this hunk.
+		last := i
+		for j := i + 1; j < len(lines) && j-last <= 2*context+1; j++ {
+			if lines[j].Op != Equal {
+				last = j
+			}
+		}
+
+		start, end := i-context, last+1+context
+		if start < 0 {
+			start = 0
+		}
+		if end > len(lines) {
+			end = len(lines)
+		}
+		h := Hunk{
+			AStart: aPos[start],
+			ALen:   aPos[end] - aPos[start],
+			BStart: bPos[start],
+			BLen:   bPos[end] - bPos[start],
+			Lines:  lines[start:end],
+		}
+		if section != nil {
+			h.Section = section(aLines, h.AStart)
+		}
+		hunks = append(hunks, h)
+		i = last
+	}
+	return hunks
+}
+
+// RenderHunks renders the hunks in unified diff format.  Each hunk begins with
+// a header of the form
+//
+//	@@ -l,s +l,s @@ section
+//
+// followed by its lines, rendered as by RenderLines.
+func RenderHunks(hunks []Hunk) string {
+	buf := new(strings.Builder)
+	for _, h := range hunks {
+		fmt.Fprintf(buf, "@@ -%s +%s @@", hunkRange(h.AStart, h.ALen), hunkRange(h.BStart, h.BLen))
+		if h.Section != "" {
+			fmt.Fprintf(buf, " %s", h.Section)
+		}
+		buf.WriteByte('\n')
+		for _, l := range h.Lines {
+			buf.WriteByte(l.prefix())
+			buf.WriteString(l.Text)
+			buf.WriteByte('\n')
+		}
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// hunkRange formats the range of a hunk on one side in unified diff format.
+func hunkRange(start, length int) string {
+	switch length {
+	case 0:
+		// An empty range refers to the line before the hunk.
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
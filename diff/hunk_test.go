@@ -0,0 +1,145 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHunks(t *testing.T) {
+	tests := []struct {
+		desc    string
+		A, B    string
+		context int
+		out     string
+	}{
+		{
+			desc: "same",
+			A:    "a\nb\nc",
+			B:    "a\nb\nc",
+		},
+		{
+			desc:    "one change",
+			A:       "1\n2\n3\n4\n5\n6\n7",
+			B:       "1\n2\n3\nfour\n5\n6\n7",
+			context: 1,
+			out: strings.TrimSpace(`
+@@ -3,3 +3,3 @@
+ 3
+-4
++four
+ 5
+			`),
+		},
+		{
+			desc:    "two hunks",
+			A:       "1\n2\n3\n4\n5\n6\n7\n8",
+			B:       "one\n2\n3\n4\n5\n6\n7\neight",
+			context: 2,
+			out: strings.TrimSpace(`
+@@ -1,3 +1,3 @@
+-1
++one
+ 2
+ 3
+@@ -6,3 +6,3 @@
+ 6
+ 7
+-8
++eight
+			`),
+		},
+		{
+			desc:    "merged hunks",
+			A:       "1\n2\n3\n4\n5\n6",
+			B:       "one\n2\n3\n4\n5\nsix",
+			context: 2,
+			out: strings.TrimSpace(`
+@@ -1,6 +1,6 @@
+-1
++one
+ 2
+ 3
+ 4
+ 5
+-6
++six
+			`),
+		},
+		{
+			desc:    "insert only",
+			A:       "1\n2",
+			B:       "1\n2\n3",
+			context: 0,
+			out: strings.TrimSpace(`
+@@ -2,0 +3 @@
++3
+			`),
+		},
+		{
+			desc:    "delete only",
+			A:       "1\n2\n3",
+			B:       "2\n3",
+			context: 0,
+			out: strings.TrimSpace(`
+@@ -1 +0,0 @@
+-1
+			`),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			chunks := DiffChunks(strings.Split(test.A, "\n"), strings.Split(test.B, "\n"))
+			hunks := Hunks(Lines(chunks), test.context, nil)
+			if got, want := RenderHunks(hunks), test.out; got != want {
+				t.Errorf("GOT\n%s", got)
+				t.Errorf("WANT\n%s", want)
+			}
+		})
+	}
+}
+
+func ExampleHunks() {
+	before := strings.TrimSpace(`
+package main
+
+func main() {
+	fmt.Println("Hello")
+	fmt.Println("World")
+}
+`)
+
+	after := strings.TrimSpace(`
+package main
+
+func main() {
+	fmt.Println("Hello")
+	fmt.Println("Gophers")
+}
+`)
+
+	chunks := DiffChunks(strings.Split(before, "\n"), strings.Split(after, "\n"))
+	fmt.Println(RenderHunks(Hunks(Lines(chunks), 1, GoSection)))
+
+	// Output:
+	// @@ -4,3 +4,3 @@ func main() {
+	//  	fmt.Println("Hello")
+	// -	fmt.Println("World")
+	// +	fmt.Println("Gophers")
+	//  }
+}
@@ -0,0 +1,119 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Span is a range of bytes [Start, End) within the Text of a Line.
+type Span struct {
+	Start, End int
+}
+
+// Refine computes intra-line changes for the edit script.  Each run of deleted
+// lines which is immediately followed by a run of added lines is paired up
+// line by line, and the words which differ between the lines of each pair are
+// recorded in their Spans.  Lines are modified in place and returned for
+// convenience.
+//
+// Words are runs of letters, digits, and underscores; runs of whitespace and
+// individual punctuation characters are compared separately.
+func Refine(lines []Line) []Line {
+	for i := 0; i < len(lines); {
+		if lines[i].Op != Deleted {
+			i++
+			continue
+		}
+		del := i
+		for i < len(lines) && lines[i].Op == Deleted {
+			i++
+		}
+		add := i
+		for i < len(lines) && lines[i].Op == Added {
+			i++
+		}
+		for k := 0; del+k < add && add+k < i; k++ {
+			refinePair(&lines[del+k], &lines[add+k])
+		}
+	}
+	return lines
+}
+
+// refinePair records the differing words of a deleted and an added line.
+func refinePair(del, add *Line) {
+	delWords, addWords := words(del.Text), words(add.Text)
+	del.Spans, add.Spans = nil, nil
+
+	var delPos, addPos int
+	for _, c := range DiffChunks(delWords, addWords) {
+		for _, w := range c.Deleted {
+			del.Spans = addSpan(del.Spans, delPos, delPos+len(w))
+			delPos += len(w)
+		}
+		for _, w := range c.Added {
+			add.Spans = addSpan(add.Spans, addPos, addPos+len(w))
+			addPos += len(w)
+		}
+		for _, w := range c.Equal {
+			delPos += len(w)
+			addPos += len(w)
+		}
+	}
+}
+
+// addSpan appends [start, end) to spans, extending the last span if they touch.
+func addSpan(spans []Span, start, end int) []Span {
+	if n := len(spans); n > 0 && spans[n-1].End == start {
+		spans[n-1].End = end
+		return spans
+	}
+	return append(spans, Span{start, end})
+}
+
+// words splits text into words, runs of whitespace, and other characters.
+func words(text string) []string {
+	var out []string
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		n := size
+		switch {
+		case isWordRune(r):
+			for n < len(text) {
+				r, size := utf8.DecodeRuneInString(text[n:])
+				if !isWordRune(r) {
+					break
+				}
+				n += size
+			}
+		case unicode.IsSpace(r):
+			for n < len(text) {
+				r, size := utf8.DecodeRuneInString(text[n:])
+				if !unicode.IsSpace(r) {
+					break
+				}
+				n += size
+			}
+		}
+		out = append(out, text[:n])
+		text = text[n:]
+	}
+	return out
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
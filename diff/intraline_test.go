@@ -0,0 +1,82 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRefine(t *testing.T) {
+	tests := []struct {
+		desc     string
+		A, B     []string
+		delSpans []string
+		addSpans []string
+	}{
+		{
+			desc:     "one word",
+			A:        []string{"the quick brown fox"},
+			B:        []string{"the slow brown fox"},
+			delSpans: []string{"quick"},
+			addSpans: []string{"slow"},
+		},
+		{
+			desc:     "punctuation",
+			A:        []string{"f(a, b)"},
+			B:        []string{"f(a, b, c)"},
+			addSpans: []string{", c"},
+		},
+		{
+			desc:     "whole line",
+			A:        []string{"old"},
+			B:        []string{"new"},
+			delSpans: []string{"old"},
+			addSpans: []string{"new"},
+		},
+		{
+			desc:     "unicode",
+			A:        []string{"héllo wörld"},
+			B:        []string{"héllo wereld"},
+			delSpans: []string{"wörld"},
+			addSpans: []string{"wereld"},
+		},
+	}
+
+	spanText := func(l Line) (out []string) {
+		for _, s := range l.Spans {
+			out = append(out, l.Text[s.Start:s.End])
+		}
+		return out
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			lines := Refine(Lines(DiffChunks(test.A, test.B)))
+			for _, l := range lines {
+				var want []string
+				switch l.Op {
+				case Deleted:
+					want = test.delSpans
+				case Added:
+					want = test.addSpans
+				}
+				if got := spanText(l); !reflect.DeepEqual(got, want) {
+					t.Errorf("%v %q: spans %q, want %q", l.Op, l.Text, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The types in this package serialize to and from JSON using the following
+// schema.  Fields which are empty are omitted, and all line indices are
+// zero-based, as in the Go types.
+//
+//	Op:    "equal" | "deleted" | "added"
+//	Span:  [start, end]
+//	Line:  {"op": Op, "text": string, "a": int, "b": int,
+//	        "move": int, "spans": [Span...]}
+//	Hunk:  {"a": {"start": int, "len": int}, "b": {"start": int, "len": int},
+//	        "section": string, "lines": [Line...]}
+//	Chunk: {"deleted": [string...], "added": [string...], "equal": [string...]}
+//
+// The "a" and "b" fields of a Line are omitted for a line which is not present
+// on that side.  Decoding the JSON for a value always results in a value that
+// is equal to the original, except that empty slices are decoded as nil.
+
+// MarshalJSON encodes the Op as its name.
+func (o Op) MarshalJSON() ([]byte, error) {
+	name, ok := opNames[o]
+	if !ok {
+		return nil, fmt.Errorf("diff: cannot marshal unknown %v", o)
+	}
+	return json.Marshal(name)
+}
+
+// UnmarshalJSON decodes the Op from its name.
+func (o *Op) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	for op, opName := range opNames {
+		if name == opName {
+			*o = op
+			return nil
+		}
+	}
+	return fmt.Errorf("diff: unknown op %q", name)
+}
+
+// MarshalJSON encodes the Span as a two-element array.
+func (s Span) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]int{s.Start, s.End})
+}
+
+// UnmarshalJSON decodes the Span from a two-element array.
+func (s *Span) UnmarshalJSON(data []byte) error {
+	var pair [2]int
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	s.Start, s.End = pair[0], pair[1]
+	return nil
+}
+
+type jsonLine struct {
+	Op    Op     `json:"op"`
+	Text  string `json:"text"`
+	A     *int   `json:"a,omitempty"`
+	B     *int   `json:"b,omitempty"`
+	Move  int    `json:"move,omitempty"`
+	Spans []Span `json:"spans,omitempty"`
+}
+
+// MarshalJSON encodes the Line as a JSON object.
+func (l Line) MarshalJSON() ([]byte, error) {
+	jl := jsonLine{
+		Op:    l.Op,
+		Text:  l.Text,
+		Move:  l.Move,
+		Spans: l.Spans,
+	}
+	if l.A >= 0 {
+		jl.A = &l.A
+	}
+	if l.B >= 0 {
+		jl.B = &l.B
+	}
+	return json.Marshal(jl)
+}
+
+// UnmarshalJSON decodes the Line from a JSON object.
+func (l *Line) UnmarshalJSON(data []byte) error {
+	var jl jsonLine
+	if err := json.Unmarshal(data, &jl); err != nil {
+		return err
+	}
+	*l = Line{
+		Op:    jl.Op,
+		Text:  jl.Text,
+		A:     -1,
+		B:     -1,
+		Move:  jl.Move,
+		Spans: jl.Spans,
+	}
+	if jl.A != nil {
+		l.A = *jl.A
+	}
+	if jl.B != nil {
+		l.B = *jl.B
+	}
+	return nil
+}
+
+type jsonRange struct {
+	Start int `json:"start"`
+	Len   int `json:"len"`
+}
+
+type jsonHunk struct {
+	A       jsonRange `json:"a"`
+	B       jsonRange `json:"b"`
+	Section string    `json:"section,omitempty"`
+	Lines   []Line    `json:"lines"`
+}
+
+// MarshalJSON encodes the Hunk as a JSON object.
+func (h Hunk) MarshalJSON() ([]byte, error) {
+	lines := h.Lines
+	if lines == nil {
+		lines = []Line{}
+	}
+	return json.Marshal(jsonHunk{
+		A:       jsonRange{h.AStart, h.ALen},
+		B:       jsonRange{h.BStart, h.BLen},
+		Section: h.Section,
+		Lines:   lines,
+	})
+}
+
+// UnmarshalJSON decodes the Hunk from a JSON object.
+func (h *Hunk) UnmarshalJSON(data []byte) error {
+	var jh jsonHunk
+	if err := json.Unmarshal(data, &jh); err != nil {
+		return err
+	}
+	*h = Hunk{
+		AStart:  jh.A.Start,
+		ALen:    jh.A.Len,
+		BStart:  jh.B.Start,
+		BLen:    jh.B.Len,
+		Section: jh.Section,
+		Lines:   jh.Lines,
+	}
+	if len(h.Lines) == 0 {
+		h.Lines = nil
+	}
+	return nil
+}
+
+type jsonChunk struct {
+	Deleted []string `json:"deleted,omitempty"`
+	Added   []string `json:"added,omitempty"`
+	Equal   []string `json:"equal,omitempty"`
+}
+
+// MarshalJSON encodes the Chunk as a JSON object.
+func (c Chunk) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonChunk{
+		Deleted: c.Deleted,
+		Added:   c.Added,
+		Equal:   c.Equal,
+	})
+}
+
+// UnmarshalJSON decodes the Chunk from a JSON object.
+func (c *Chunk) UnmarshalJSON(data []byte) error {
+	var jc jsonChunk
+	if err := json.Unmarshal(data, &jc); err != nil {
+		return err
+	}
+	*c = Chunk{
+		Added:   jc.Added,
+		Deleted: jc.Deleted,
+		Equal:   jc.Equal,
+	}
+	return nil
+}
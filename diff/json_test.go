@@ -0,0 +1,98 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	a := strings.Split("one\ntwo\nthree\nfour\nfive\nsix", "\n")
+	b := strings.Split("four\nfive\nsix\none\ntwo\nthree!", "\n")
+
+	chunks := DiffChunks(a, b)
+	lines := Refine(DetectMoves(Lines(chunks), 2))
+	hunks := Hunks(lines, 1, func([]string, int) string { return "section" })
+
+	tests := []struct {
+		desc  string
+		value interface{}
+		ptr   interface{}
+	}{
+		{"chunks", chunks, new([]Chunk)},
+		{"lines", lines, new([]Line)},
+		{"hunks", hunks, new([]Hunk)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			data, err := json.Marshal(test.value)
+			if err != nil {
+				t.Fatalf("Marshal: %s", err)
+			}
+			if err := json.Unmarshal(data, test.ptr); err != nil {
+				t.Fatalf("Unmarshal(%s): %s", data, err)
+			}
+			if got, want := reflect.ValueOf(test.ptr).Elem().Interface(), test.value; !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip of %s:", data)
+				t.Errorf("GOT  %+v", got)
+				t.Errorf("WANT %+v", want)
+			}
+		})
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	tests := []struct {
+		desc string
+		json string
+		ptr  interface{}
+	}{
+		{"unknown op", `{"op": "renamed", "text": ""}`, new(Line)},
+		{"bad span", `{"op": "added", "text": "", "spans": [1]}`, new(Line)},
+		{"bad hunk", `{"a": 1}`, new(Hunk)},
+		{"bad chunk", `{"equal": "x"}`, new(Chunk)},
+	}
+
+	for _, test := range tests {
+		if err := json.Unmarshal([]byte(test.json), test.ptr); err == nil {
+			t.Errorf("%s: Unmarshal(%s) succeeded, want error", test.desc, test.json)
+		}
+	}
+
+	if _, err := json.Marshal(Op(42)); err == nil {
+		t.Errorf("Marshal(Op(42)) succeeded, want error")
+	}
+}
+
+func ExampleLine_MarshalJSON() {
+	lines := Refine(Lines(DiffChunks(
+		[]string{"context", "Hello, World"},
+		[]string{"context", "Hello, Gophers"},
+	)))
+	for _, l := range lines {
+		data, _ := json.Marshal(l)
+		fmt.Println(string(data))
+	}
+
+	// Output:
+	// {"op":"equal","text":"context","a":0,"b":0}
+	// {"op":"deleted","text":"Hello, World","a":1,"spans":[[7,12]]}
+	// {"op":"added","text":"Hello, Gophers","b":1,"spans":[[7,14]]}
+}
@@ -0,0 +1,115 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"strings"
+)
+
+// DetectMoves finds blocks of at least minLines lines which were deleted in
+// one place and added elsewhere in the edit script, and marks both halves of
+// each block with a shared Move number.  The lines are modified in place and
+// returned for convenience.
+//
+// Lines are considered to match if they are the same after leading and
+// trailing whitespace is removed, so a block that was moved and reindented is
+// still detected.  A block which was only reindented in place, such as when it
+// is wrapped in a new if statement, is not a move, so the halves of a block
+// must be in different runs of changed lines.  Blocks consisting only of blank
+// lines are never considered to have moved.
+func DetectMoves(lines []Line, minLines int) []Line {
+	if minLines < 1 {
+		minLines = 1
+	}
+
+	// Number the runs of changed lines, which are separated by equal lines.
+	runs := make([]int, len(lines))
+	run := 0
+	for i, l := range lines {
+		if l.Op == Equal {
+			run++
+		}
+		runs[i] = run
+	}
+
+	// Index the added lines by their content for quick lookup.
+	added := make(map[string][]int)
+	for i, l := range lines {
+		if l.Op == Added {
+			key := moveKey(l.Text)
+			added[key] = append(added[key], i)
+		}
+	}
+
+	var moves int
+	for i := 0; i < len(lines); i++ {
+		if lines[i].Op != Deleted || lines[i].Move != 0 {
+			continue
+		}
+
+		// Find the longest block starting here which was added somewhere.
+		bestStart, bestLen := 0, 0
+		for _, j := range added[moveKey(lines[i].Text)] {
+			if runs[j] == runs[i] {
+				continue // changed in place
+			}
+			n := matchingRun(lines, i, j)
+			if n > bestLen {
+				bestStart, bestLen = j, n
+			}
+		}
+		if bestLen < minLines || blank(lines[i:i+bestLen]) {
+			continue
+		}
+
+		moves++
+		for k := 0; k < bestLen; k++ {
+			lines[i+k].Move = moves
+			lines[bestStart+k].Move = moves
+		}
+		i += bestLen - 1
+	}
+	return lines
+}
+
+// matchingRun returns how many consecutive unmoved deleted lines starting at
+// del match the consecutive unmoved added lines starting at add.
+func matchingRun(lines []Line, del, add int) int {
+	n := 0
+	for del+n < len(lines) && add+n < len(lines) {
+		d, a := lines[del+n], lines[add+n]
+		if d.Op != Deleted || d.Move != 0 || a.Op != Added || a.Move != 0 {
+			break
+		}
+		if moveKey(d.Text) != moveKey(a.Text) {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+func moveKey(text string) string {
+	return strings.TrimSpace(text)
+}
+
+func blank(lines []Line) bool {
+	for _, l := range lines {
+		if moveKey(l.Text) != "" {
+			return false
+		}
+	}
+	return true
+}
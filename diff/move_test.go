@@ -0,0 +1,158 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectMoves(t *testing.T) {
+	tests := []struct {
+		desc     string
+		A, B     []string
+		minLines int
+		out      string
+	}{
+		{
+			desc: "swapped functions",
+			A: []string{
+				"func a() {",
+				"	x()",
+				"}",
+				"",
+				"func b() {",
+				"	y()",
+				"}",
+			},
+			B: []string{
+				"func b() {",
+				"	y()",
+				"}",
+				"",
+				"func a() {",
+				"	x()",
+				"}",
+			},
+			minLines: 2,
+			out: strings.TrimSpace(`
+<func a() {
+<	x()
+<}
+-
+ func b() {
+ 	y()
+ }
++
+>func a() {
+>	x()
+>}
+			`),
+		},
+		{
+			desc:     "reindented",
+			A:        []string{"one", "two", "three", "four"},
+			B:        []string{"three", "four", "  one", "  two"},
+			minLines: 2,
+			out: strings.TrimSpace(`
+<one
+<two
+ three
+ four
+>  one
+>  two
+			`),
+		},
+		{
+			desc:     "reindented in place",
+			A:        []string{"one()", "two()", "end()"},
+			B:        []string{"if ok {", "	one()", "	two()", "}", "end()"},
+			minLines: 2,
+			out: strings.TrimSpace(`
+-one()
+-two()
++if ok {
++	one()
++	two()
++}
+ end()
+			`),
+		},
+		{
+			desc:     "too short",
+			A:        []string{"one", "two", "three"},
+			B:        []string{"two", "three", "one"},
+			minLines: 2,
+			out: strings.TrimSpace(`
+-one
+ two
+ three
++one
+			`),
+		},
+		{
+			desc:     "blank lines",
+			A:        []string{"", "", "x", "y", "z"},
+			B:        []string{"x", "y", "z", "", ""},
+			minLines: 1,
+			out: strings.TrimSpace(`
+-
+-
+ x
+ y
+ z
++
++
+			`),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			lines := DetectMoves(Lines(DiffChunks(test.A, test.B)), test.minLines)
+			if got, want := RenderLines(lines), test.out; got != want {
+				t.Errorf("GOT\n%s", got)
+				t.Errorf("WANT\n%s", want)
+			}
+		})
+	}
+}
+
+func TestDetectMovesPairs(t *testing.T) {
+	a := []string{"a", "b", "c", "d", "e", "f"}
+	b := []string{"e", "f", "c", "d", "a", "b"}
+
+	lines := DetectMoves(Lines(DiffChunks(a, b)), 2)
+
+	from := make(map[int][]string)
+	to := make(map[int][]string)
+	for _, l := range lines {
+		switch {
+		case l.Move == 0:
+		case l.Op == Deleted:
+			from[l.Move] = append(from[l.Move], l.Text)
+		case l.Op == Added:
+			to[l.Move] = append(to[l.Move], l.Text)
+		}
+	}
+	if len(from) == 0 {
+		t.Fatalf("no moves detected:\n%s", RenderLines(lines))
+	}
+	for id, text := range from {
+		if got, want := strings.Join(to[id], ","), strings.Join(text, ","); got != want {
+			t.Errorf("move %d: added %q, deleted %q", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"go/scanner"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// GoSection is a SectionFunc for Go source code.  It returns the first line of
+// the nearest top-level func or type declaration which starts before the hunk.
+//
+// The source is tokenized with go/scanner, so declarations which appear inside
+// comments, strings, or function bodies are not mistaken for sections.
+func GoSection(lines []string, index int) string {
+	if index > len(lines) {
+		index = len(lines)
+	}
+	src := []byte(strings.Join(lines[:index], "\n"))
+
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var s scanner.Scanner
+	s.Init(file, src, nil, 0)
+
+	var depth, found int
+	startOfDecl := true
+	for {
+		pos, tok, _ := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		switch tok {
+		case token.LBRACE, token.LPAREN, token.LBRACK:
+			depth++
+		case token.RBRACE, token.RPAREN, token.RBRACK:
+			if depth > 0 {
+				depth--
+			}
+		case token.FUNC, token.TYPE:
+			if depth == 0 && startOfDecl {
+				found = file.Line(pos)
+			}
+		}
+		startOfDecl = depth == 0 && tok == token.SEMICOLON
+	}
+	if found == 0 {
+		return ""
+	}
+	return strings.TrimRight(lines[found-1], " \t\r")
+}
+
+// RegexpSection returns a SectionFunc which returns the nearest line before
+// the hunk which matches re.  This is how git finds hunk headings by default,
+// using a pattern like `^[[:alpha:]$_]`.
+func RegexpSection(re *regexp.Regexp) SectionFunc {
+	return func(lines []string, index int) string {
+		if index > len(lines) {
+			index = len(lines)
+		}
+		for i := index - 1; i >= 0; i-- {
+			if re.MatchString(lines[i]) {
+				return strings.TrimRight(lines[i], " \t\r")
+			}
+		}
+		return ""
+	}
+}
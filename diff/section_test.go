@@ -0,0 +1,83 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var goSource = strings.Split(`package example
+
+// func notThisOne() {}
+type Point struct {
+	X, Y int
+}
+
+func (p Point) String() string {
+	s := "func inString() {"
+	f := func() {
+		return
+	}
+	return s
+}
+
+var handler = func() {
+	work()
+}
+`, "\n")
+
+func TestGoSection(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, ""},
+		{3, ""},
+		{5, "type Point struct {"},
+		{9, "func (p Point) String() string {"},
+		{12, "func (p Point) String() string {"},
+		{17, "func (p Point) String() string {"},
+		{100, "func (p Point) String() string {"},
+	}
+
+	for _, test := range tests {
+		if got, want := GoSection(goSource, test.index), test.want; got != want {
+			t.Errorf("GoSection(src, %d) = %q, want %q", test.index, got, want)
+		}
+	}
+}
+
+func TestRegexpSection(t *testing.T) {
+	section := RegexpSection(regexp.MustCompile(`^[[:alpha:]$_]`))
+
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, ""},
+		{1, "package example"},
+		{4, "type Point struct {"},
+		{9, "func (p Point) String() string {"},
+		{17, "var handler = func() {"},
+	}
+
+	for _, test := range tests {
+		if got, want := section(goSource, test.index), test.want; got != want {
+			t.Errorf("section(src, %d) = %q, want %q", test.index, got, want)
+		}
+	}
+}
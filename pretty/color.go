@@ -0,0 +1,127 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// A ColorMode selects when output is colored.
+type ColorMode int
+
+// Color modes.
+const (
+	NeverColor  ColorMode = iota // Output is never colored.
+	AutoColor                    // Output is colored when written to a terminal.
+	AlwaysColor                  // Output is always colored.
+)
+
+// A Theme holds the colors of the parts of the output, as the parameters of
+// ANSI SGR escape sequences, such as "32" for green or "1;31" for bold red.
+// Parts whose color is empty are not colored.
+type Theme struct {
+	Key    string // struct field names and map keys
+	String string // strings
+	Number string // integers, floats, and complex numbers
+	Nil    string // nil
+	Bool   string // true and false
+	Cycle  string // the markers for values which refer to themselves
+	Type   string // the type names added by Types and StrictTypes
+}
+
+// DefaultTheme is the Theme used when Config.Theme is nil.
+var DefaultTheme = &Theme{
+	Key:    "36",
+	String: "32",
+	Number: "33",
+	Nil:    "90",
+	Bool:   "35",
+	Cycle:  "31",
+	Type:   "34",
+}
+
+// noColors is the theme of output which is not colored.
+var noColors = new(Theme)
+
+// colors returns the theme with which to color output written to w, which is
+// noColors if the output should not be colored.  The output of Sprint and the like is
+// written to a nil w.
+//
+// With AutoColor, output is only colored if w is a terminal, it is not
+// Diffable, the NO_COLOR environment variable is not set, and TERM is not
+// "dumb".
+func (cfg *Config) colors(w io.Writer) *Theme {
+	switch cfg.Color {
+	case AutoColor:
+		if cfg.Diffable || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" || !isTerminal(w) {
+			return noColors
+		}
+	case AlwaysColor:
+	default:
+		return noColors
+	}
+	if cfg.Theme == nil {
+		return DefaultTheme
+	}
+	return cfg.Theme
+}
+
+// isTerminal returns whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// paint writes s in the given color, if any.
+func (f *formatter) paint(color, s string) {
+	if color == "" {
+		f.WriteString(s)
+		return
+	}
+	f.WriteString("\x1b[" + color + "m")
+	f.WriteString(s)
+	f.WriteString("\x1b[0m")
+}
+
+// rawColor returns the color of the raw value s under theme t.  Raw values
+// other than nil, booleans and numbers, such as the output of Formatters, are
+// not colored.
+func (t *Theme) rawColor(s string) string {
+	if *t == (Theme{}) {
+		return ""
+	}
+	switch s {
+	case "nil":
+		return t.Nil
+	case "true", "false":
+		return t.Bool
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return t.Number
+	}
+	if _, err := strconv.ParseUint(s, 0, 64); err == nil {
+		return t.Number
+	}
+	if _, err := strconv.ParseComplex(s, 128); err == nil {
+		return t.Number
+	}
+	return ""
+}
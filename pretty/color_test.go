@@ -0,0 +1,164 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testTheme uses a different single-digit color for each part.
+var testTheme = &Theme{
+	Key:    "1",
+	String: "2",
+	Number: "3",
+	Nil:    "4",
+	Bool:   "5",
+	Cycle:  "6",
+	Type:   "7",
+}
+
+// paint returns s in the given color, for comparison with colored output.
+func paint(color, s string) string {
+	return "\x1b[" + color + "m" + s + "\x1b[0m"
+}
+
+func TestColors(t *testing.T) {
+	type entry struct {
+		Name  string
+		Score float64
+		Valid bool
+		Next  *entry
+		Extra interface{}
+	}
+	cyclic := &entry{Name: "a"}
+	cyclic.Next = cyclic
+
+	tests := []struct {
+		desc string
+		cfg  Config
+		val  interface{}
+		want string
+	}{
+		{
+			desc: "compact",
+			cfg:  Config{Compact: true},
+			val:  entry{Name: "ford", Score: -1.5, Extra: 3 + 4i},
+			want: "{" + paint("1", "Name") + ":" + paint("2", `"ford"`) +
+				"," + paint("1", "Score") + ":" + paint("3", "-1.5") +
+				"," + paint("1", "Valid") + ":" + paint("5", "false") +
+				"," + paint("1", "Next") + ":" + paint("4", "nil") +
+				"," + paint("1", "Extra") + ":" + paint("3", "(3+4i)") + "}",
+		},
+		{
+			desc: "aligned",
+			cfg:  Config{},
+			val:  map[string]int{"a": 1, "bcd": 0x10},
+			want: "{" + paint("1", "a") + ":   " + paint("3", "1") + ",\n" +
+				" " + paint("1", "bcd") + ": " + paint("3", "16") + "}",
+		},
+		{
+			desc: "cycles",
+			cfg:  Config{Compact: true, TrackCycles: true, IgnorePaths: []string{"Name", "Score", "Valid", "Extra"}},
+			val:  cyclic,
+			want: paint("6", "<#1>") + " {" + paint("1", "Next") + ":" + paint("6", "<see #1>") + "}",
+		},
+		{
+			desc: "types",
+			cfg:  Config{Compact: true, Types: InterfaceTypes},
+			val:  []interface{}{int8(1), []string{}},
+			want: "[" + paint("7", "int8") + "(" + paint("3", "1") + ")," + paint("7", "[]string") + "[]]",
+		},
+		{
+			desc: "short list",
+			cfg:  Config{ShortList: 10},
+			val:  [][]int{{1, 2}},
+			want: "[[" + paint("3", "1") + "," + paint("3", "2") + "]]",
+		},
+		{
+			desc: "formatters",
+			cfg:  Config{Compact: true, Formatter: DefaultFormatter},
+			val:  []interface{}{time.Unix(0, 0).UTC(), nil},
+			want: "[1970-01-01 00:00:00 +0000 UTC," + paint("4", "nil") + "]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			test.cfg.Color = AlwaysColor
+			test.cfg.Theme = testTheme
+			if got, want := test.cfg.Sprint(test.val), test.want; got != want {
+				t.Errorf("Sprint:\ngot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestColorModes(t *testing.T) {
+	val := map[string]interface{}{"a": []int{1}}
+	tests := []struct {
+		desc    string
+		cfg     Config
+		colored bool
+	}{
+		{
+			desc: "never",
+			cfg:  Config{Color: NeverColor},
+		},
+		{
+			desc: "auto",
+			cfg:  Config{Color: AutoColor},
+		},
+		{
+			desc:    "always",
+			cfg:     Config{Color: AlwaysColor},
+			colored: true,
+		},
+		{
+			desc:    "always diffable",
+			cfg:     Config{Color: AlwaysColor, Diffable: true},
+			colored: true,
+		},
+		{
+			desc: "always JSON",
+			cfg:  Config{Color: AlwaysColor, JSON: true},
+		},
+		{
+			desc: "always YAML",
+			cfg:  Config{Color: AlwaysColor, YAML: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if _, err := test.cfg.Fprint(buf, val); err != nil {
+				t.Fatalf("Fprint: %s", err)
+			}
+			outputs := map[string]string{
+				"Sprint":  test.cfg.Sprint(val),
+				"Fprint":  buf.String(),
+				"Compare": test.cfg.Compare(val, map[string]interface{}{}),
+			}
+			for name, out := range outputs {
+				if got, want := strings.Contains(out, "\x1b["), test.colored; got != want {
+					t.Errorf("%s colored = %v, want %v:\n%q", name, got, want, out)
+				}
+			}
+		})
+	}
+}
@@ -19,4 +19,21 @@
 // modes (normal, compact, and extended) for advanced use.
 //
 // See the Reflect and Print examples for what the output looks like.
+//
+// How a struct field is printed can be configured at the type definition with
+// a `pretty:"..."` tag, in the style of encoding/json.  The tag is an optional
+// name under which to print the field, followed by comma-separated options:
+//
+//	Internal  int    `pretty:"-"`          // never printed
+//	UserID    string `pretty:"user"`       // printed as "user"
+//	Nickname  string `pretty:",omitempty"` // skipped if it has a zero value
+//	Password  string `pretty:",redact"`    // printed as <redacted>
+//	Location  Point  `pretty:",compact"`   // printed on one line
+//
+// Secrets can also be redacted by name, using Config.RedactNames, or by type,
+// by implementing Redactable.  Redacted values are printed as a placeholder in
+// every mode and are left out of comparisons, so they are never shown.
+//
+// Paths, such as those used by Config.IgnorePaths and reported by
+// Config.Differences, always use the Go names of fields.
 package pretty
@@ -371,3 +371,69 @@ func ExampleCompare_withCycles() {
 	//   },
 	//  }
 }
+
+func ExampleDiff() {
+	type ShipManifest struct {
+		Name     string
+		Crew     map[string]string
+		Androids int
+		Stolen   bool
+	}
+
+	reported := &ShipManifest{
+		Name: "Spaceship Heart of Gold",
+		Crew: map[string]string{
+			"Zaphod Beeblebrox": "Galactic President",
+			"Trillian":          "Human",
+			"Ford Prefect":      "A Hoopy Frood",
+		},
+		Androids: 1,
+		Stolen:   true,
+	}
+
+	expected := &ShipManifest{
+		Name: "Spaceship Heart of Gold",
+		Crew: map[string]string{
+			"Trillian":          "Human",
+			"Zaphod Beeblebrox": "Just this guy, you know?",
+			"Rowan Artosok":     "Captain",
+		},
+		Androids: 1,
+		Stolen:   false,
+	}
+
+	fmt.Println(pretty.Diff(reported, expected))
+	// Output:
+	// .Crew["Ford Prefect"]: removed "A Hoopy Frood"
+	// .Crew["Rowan Artosok"]: added "Captain"
+	// .Crew["Zaphod Beeblebrox"]: "Galactic President" -> "Just this guy, you know?"
+	// .Stolen: true -> false
+}
+
+func ExampleTransform() {
+	type ShipManifest struct {
+		Name     string
+		Crew     []string
+		Androids int
+	}
+
+	manifest := ShipManifest{
+		Name:     "Spaceship Heart of Gold",
+		Crew:     []string{"Zaphod Beeblebrox", "Trillian", "Ford Prefect"},
+		Androids: 1,
+	}
+
+	// Leave out anything that isn't a string.
+	tree := pretty.Transform(pretty.Reflect(manifest), func(n pretty.Node) pretty.Node {
+		if _, ok := n.(pretty.RawVal); ok {
+			return nil
+		}
+		return n
+	})
+	fmt.Println(pretty.SprintNode(tree))
+	// Output:
+	// {Name: "Spaceship Heart of Gold",
+	//  Crew: ["Zaphod Beeblebrox",
+	//         "Trillian",
+	//         "Ford Prefect"]}
+}
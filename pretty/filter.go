@@ -0,0 +1,133 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"reflect"
+	"strings"
+)
+
+// A pathPattern is a parsed IgnorePaths pattern.  Each element is either a
+// string to compare against the String of a single PathStep, or one of the
+// wildcards anySteps and anyElem.
+type pathPattern []string
+
+const (
+	anySteps = "*"   // matches any number of steps, including none
+	anyElem  = "[*]" // matches any single Index or MapKey
+)
+
+// parsePattern parses an IgnorePaths pattern, such as "*.CreatedAt" or
+// `Items[*].Labels["owner"]`.  The leading dot of a field may be omitted at the
+// start of the pattern.  Text which cannot be parsed as a step, such as an
+// unterminated bracket, is kept as a literal step which matches nothing.
+func parsePattern(s string) pathPattern {
+	var pat pathPattern
+	for len(s) > 0 {
+		var step string
+		switch s[0] {
+		case '[':
+			step = s[:bracketEnd(s)]
+		case '.':
+			s = s[1:]
+			fallthrough
+		default:
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if step = s[:end]; step != anySteps {
+				step = "." + step
+			}
+			s = s[end:]
+			pat = append(pat, step)
+			continue
+		}
+		s = s[len(step):]
+		pat = append(pat, step)
+	}
+	return pat
+}
+
+// bracketEnd returns the index just past the ']' which closes the bracket at
+// the start of s, skipping over any quoted strings within it, or len(s) if it
+// is unterminated.
+func bracketEnd(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case c == '"' || c == '`':
+			quote = c
+		case c == ']':
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// match returns whether the pattern matches the whole of path.
+func (pat pathPattern) match(path Path) bool {
+	if len(pat) == 0 {
+		return len(path) == 0
+	}
+	switch step := pat[0]; step {
+	case anySteps:
+		for i := 0; i <= len(path); i++ {
+			if pat[1:].match(path[i:]) {
+				return true
+			}
+		}
+		return false
+	case anyElem:
+		if len(path) == 0 {
+			return false
+		}
+		if _, ok := path[0].(Field); ok {
+			return false
+		}
+	default:
+		if len(path) == 0 || path[0].String() != step {
+			return false
+		}
+	}
+	return pat[1:].match(path[1:])
+}
+
+// ignored returns whether the value val at the current path is excluded by the
+// IgnorePaths patterns or the Ignore func.
+func (r *reflector) ignored(val reflect.Value) bool {
+	for _, pat := range r.ignore {
+		if pat.match(r.path) {
+			return true
+		}
+	}
+	return r.Ignore != nil && r.Ignore(r.path, val)
+}
+
+// ignoredField returns whether the named field of the struct type typ is
+// excluded by IgnoreFields.
+func (cfg *Config) ignoredField(typ reflect.Type, name string) bool {
+	for _, ignore := range cfg.IgnoreFields[typ] {
+		if ignore == name {
+			return true
+		}
+	}
+	return false
+}
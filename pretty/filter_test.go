@@ -0,0 +1,220 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		desc    string
+		pattern string
+		want    pathPattern
+	}{
+		{
+			desc:    "field",
+			pattern: "Name",
+			want:    pathPattern{".Name"},
+		},
+		{
+			desc:    "leading dot",
+			pattern: ".Name.First",
+			want:    pathPattern{".Name", ".First"},
+		},
+		{
+			desc:    "wildcards",
+			pattern: "*.Items[*].id",
+			want:    pathPattern{"*", ".Items", "[*]", ".id"},
+		},
+		{
+			desc:    "quoted key",
+			pattern: `Labels["a]b"][0]`,
+			want:    pathPattern{".Labels", `["a]b"]`, "[0]"},
+		},
+		{
+			desc:    "unterminated",
+			pattern: "Items[0",
+			want:    pathPattern{".Items", "[0"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if got, want := parsePattern(test.pattern), test.want; !reflect.DeepEqual(got, want) {
+				t.Errorf("parsePattern(%q) = %q, want %q", test.pattern, got, want)
+			}
+		})
+	}
+}
+
+func TestPatternMatch(t *testing.T) {
+	key := func(s string) MapKey { return MapKey{reflect.ValueOf(s), s} }
+	tests := []struct {
+		desc    string
+		pattern string
+		path    Path
+		want    bool
+	}{
+		{
+			desc:    "exact",
+			pattern: "Items[3].id",
+			path:    Path{Field("Items"), Index(3), Field("id")},
+			want:    true,
+		},
+		{
+			desc:    "anchored",
+			pattern: "Items[3].id",
+			path:    Path{Field("Order"), Field("Items"), Index(3), Field("id")},
+		},
+		{
+			desc:    "any steps",
+			pattern: "*.CreatedAt",
+			path:    Path{Field("Order"), Index(1), Field("CreatedAt")},
+			want:    true,
+		},
+		{
+			desc:    "any steps matches none",
+			pattern: "*.CreatedAt",
+			path:    Path{Field("CreatedAt")},
+			want:    true,
+		},
+		{
+			desc:    "any element",
+			pattern: "Meta[*]",
+			path:    Path{Field("Meta"), key("cache")},
+			want:    true,
+		},
+		{
+			desc:    "any element is not a field",
+			pattern: "Meta[*]",
+			path:    Path{Field("Meta"), Field("cache")},
+		},
+		{
+			desc:    "map key",
+			pattern: `Meta["cache"]`,
+			path:    Path{Field("Meta"), key("cache")},
+			want:    true,
+		},
+		{
+			desc:    "too short",
+			pattern: "Items[*].id",
+			path:    Path{Field("Items"), Index(0)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if got, want := parsePattern(test.pattern).match(test.path), test.want; got != want {
+				t.Errorf("%q matches %q = %v, want %v", test.pattern, test.path, got, want)
+			}
+		})
+	}
+}
+
+func TestFilters(t *testing.T) {
+	type item struct {
+		id   int
+		Name string
+	}
+	type order struct {
+		ID        int
+		CreatedAt time.Time
+		Items     []item
+		Meta      map[string]string
+	}
+
+	a := order{
+		ID:        1,
+		CreatedAt: time.Unix(0, 0).UTC(),
+		Items:     []item{{1, "towel"}},
+		Meta:      map[string]string{"cache": "x", "owner": "ford"},
+	}
+	b := order{
+		ID:        2,
+		CreatedAt: time.Unix(60, 0).UTC(),
+		Items:     []item{{2, "towel"}},
+		Meta:      map[string]string{"cache": "y", "owner": "ford"},
+	}
+
+	tests := []struct {
+		desc  string
+		cfg   Config
+		print string
+		diff  string
+	}{
+		{
+			desc:  "none",
+			cfg:   Config{Compact: true, IncludeUnexported: true, Formatter: DefaultFormatter},
+			print: `{ID:1,CreatedAt:1970-01-01 00:00:00 +0000 UTC,Items:[{id:1,Name:"towel"}],Meta:{cache:"x",owner:"ford"}}`,
+			diff: strings.TrimSpace(`
+.ID: 1 -> 2
+.CreatedAt: 1970-01-01 00:00:00 +0000 UTC -> 1970-01-01 00:01:00 +0000 UTC
+.Items[0].id: 1 -> 2
+.Meta["cache"]: "x" -> "y"
+			`),
+		},
+		{
+			desc: "paths",
+			cfg: Config{
+				Compact:           true,
+				IncludeUnexported: true,
+				IgnorePaths:       []string{"*.CreatedAt", "Items[*].id", `Meta["cache"]`, "ID"},
+			},
+			print: `{Items:[{Name:"towel"}],Meta:{owner:"ford"}}`,
+		},
+		{
+			desc: "fields",
+			cfg: Config{
+				Compact:           true,
+				IncludeUnexported: true,
+				IgnoreFields: map[reflect.Type][]string{
+					reflect.TypeOf(order{}): {"ID", "CreatedAt", "Meta"},
+					reflect.TypeOf(item{}):  {"id"},
+				},
+			},
+			print: `{Items:[{Name:"towel"}]}`,
+		},
+		{
+			desc: "predicate",
+			cfg: Config{
+				Compact:           true,
+				IncludeUnexported: true,
+				Ignore: func(path Path, val reflect.Value) bool {
+					return val.Kind() == reflect.Int || val.Type() == reflect.TypeOf(time.Time{})
+				},
+			},
+			print: `{Items:[{Name:"towel"}],Meta:{cache:"x",owner:"ford"}}`,
+			diff:  `.Meta["cache"]: "x" -> "y"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if got, want := test.cfg.Sprint(a), test.print; got != want {
+				t.Errorf("Sprint:\ngot:  %s\nwant: %s", got, want)
+			}
+			if got, want := test.cfg.Diff(a, b), test.diff; got != want {
+				t.Errorf("Diff:\ngot:\n%s\nwant:\n%s", got, want)
+			}
+			if got, want := test.cfg.Equal(a, b), test.diff == ""; got != want {
+				t.Errorf("Equal = %v, want %v", got, want)
+			}
+		})
+	}
+}
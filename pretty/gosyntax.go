@@ -0,0 +1,465 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"fmt"
+	"go/format"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SprintGo returns val as a Go expression according to cfg, such as a
+// composite literal, along with the import paths of the packages which the
+// expression refers to.  The expression is formatted as by gofmt, on one line
+// if cfg is Compact.  See the GoSyntax option for details.
+//
+// An error is returned if val contains a value which cannot be written in Go,
+// such as a func, a chan, or a reference to itself.
+func (cfg *Config) SprintGo(val interface{}) (expr string, imports []string, err error) {
+	g := &goWriter{
+		reflector: cfg.newReflector(),
+		imports:   make(map[string]bool),
+		parents:   make(map[uintptr]bool),
+	}
+	g.write(reflect.ValueOf(val), nil, false)
+	if g.err != nil {
+		return "", nil, g.err
+	}
+
+	const prefix = "_ = "
+	src, err := format.Source([]byte(prefix + g.String()))
+	if err != nil {
+		return "", nil, fmt.Errorf("pretty: formatting Go syntax: %s", err)
+	}
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	return strings.TrimPrefix(string(src), prefix), imports, nil
+}
+
+// goWriter writes values as Go expressions.
+type goWriter struct {
+	*reflector // for filters, tags, and redaction
+	strings.Builder
+
+	imports map[string]bool  // import paths of the packages referred to
+	parents map[uintptr]bool // references being written, to detect cycles
+	err     error            // the first error encountered
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// fail records an error for the value at the current path.
+func (g *goWriter) fail(format string, args ...interface{}) {
+	if g.err != nil {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if len(g.path) > 0 {
+		msg += " at " + g.path.String()
+	}
+	g.err = fmt.Errorf("pretty: cannot write Go syntax: %s", msg)
+	g.WriteString("nil")
+}
+
+// newline starts a new line, unless the output is Compact.  Gofmt takes care of
+// the indentation.
+func (g *goWriter) newline() {
+	if !g.Compact {
+		g.WriteByte('\n')
+	}
+}
+
+// write writes val, whose static type is ctx.  A nil ctx is treated as an
+// empty interface.  If elide is true, val is an element of a composite literal
+// whose element type is ctx, so its type may be left out.
+func (g *goWriter) write(val reflect.Value, ctx reflect.Type, elide bool) {
+	if !val.IsValid() {
+		g.WriteString("nil")
+		return
+	}
+	if redacts(val) {
+		g.writeZero(val.Type(), ctx)
+		g.WriteString(" /* redacted */")
+		return
+	}
+
+	typ := val.Type()
+	exact := ctx == typ
+	switch kind := val.Kind(); kind {
+	case reflect.Interface:
+		if val.IsNil() {
+			g.writeNil(typ, ctx)
+			return
+		}
+		g.write(val.Elem(), typ, false)
+	case reflect.Ptr:
+		if val.IsNil() {
+			g.writeNil(typ, ctx)
+			return
+		}
+		if g.parents[val.Pointer()] {
+			g.fail("%v refers to itself", typ)
+			return
+		}
+		g.parents[val.Pointer()] = true
+		defer delete(g.parents, val.Pointer())
+
+		switch elem := val.Elem(); elem.Kind() {
+		case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+			if elem.Type() == timeType {
+				break
+			}
+			if !elide || !exact {
+				g.WriteByte('&')
+			}
+			g.write(elem, elem.Type(), elide && exact)
+			return
+		}
+		// There is no literal for a pointer to a basic value, so one is
+		// declared with the element type, which the constant may not imply.
+		fmt.Fprintf(g, "func() %s { var v %s = ", g.typeName(typ), g.typeName(typ.Elem()))
+		g.write(val.Elem(), typ.Elem(), false)
+		g.WriteString("; return &v }()")
+	case reflect.Struct:
+		if typ == timeType && val.CanInterface() {
+			g.writeTime(val.Interface().(time.Time))
+			return
+		}
+		if !elide || !exact {
+			g.WriteString(g.typeName(typ))
+		}
+		g.WriteByte('{')
+		first := true
+		for i := 0; i < typ.NumField(); i++ {
+			sf, field := typ.Field(i), val.Field(i)
+			g.push(Field(sf.Name))
+			if g.includeField(typ, sf, field) && !g.redactsField(sf) {
+				if sf.PkgPath != "" && sf.PkgPath != g.GoPackage {
+					// Only the field's own package may set it.
+					g.fail("%v has unexported field %s", typ, sf.Name)
+				}
+				if first {
+					g.newline()
+				}
+				first = false
+				g.WriteString(sf.Name)
+				g.WriteString(": ")
+				g.write(field, sf.Type, false)
+				g.WriteByte(',')
+				g.newline()
+			}
+			g.pop()
+		}
+		g.WriteByte('}')
+	case reflect.Slice, reflect.Array:
+		if kind == reflect.Slice {
+			if val.IsNil() {
+				g.writeNil(typ, ctx)
+				return
+			}
+			if val.Len() > 0 && g.parents[val.Pointer()] {
+				g.fail("%v refers to itself", typ)
+				return
+			}
+			g.parents[val.Pointer()] = true
+			defer delete(g.parents, val.Pointer())
+		}
+		if !elide || !exact {
+			g.WriteString(g.typeName(typ))
+		}
+		g.WriteByte('{')
+		if val.Len() > 0 {
+			g.newline()
+		}
+		for i := 0; i < val.Len(); i++ {
+			g.push(Index(i))
+			g.write(val.Index(i), typ.Elem(), true)
+			g.WriteByte(',')
+			g.newline()
+			g.pop()
+		}
+		g.WriteByte('}')
+	case reflect.Map:
+		if val.IsNil() {
+			g.writeNil(typ, ctx)
+			return
+		}
+		if val.Len() > 0 && g.parents[val.Pointer()] {
+			g.fail("%v refers to itself", typ)
+			return
+		}
+		g.parents[val.Pointer()] = true
+		defer delete(g.parents, val.Pointer())
+
+		if !elide || !exact {
+			g.WriteString(g.typeName(typ))
+		}
+		g.WriteByte('{')
+		// Sort the keys as they would be printed otherwise.
+		keys := val.MapKeys()
+		pairs := make([]mapPair, 0, len(keys))
+		for _, key := range keys {
+			pairs = append(pairs, mapPair{
+				key:  new(formatter).compactString(g.val2node(key)),
+				mkey: key,
+			})
+		}
+		sort.Sort(byKey(pairs))
+		if len(pairs) > 0 {
+			g.newline()
+		}
+		for _, pair := range pairs {
+			key := pair.mkey
+			g.push(MapKey{key, pair.key})
+			if !g.ignored(val.MapIndex(key)) {
+				g.write(key, typ.Key(), true)
+				g.WriteString(": ")
+				if g.redactsKey(key) {
+					g.writeZero(typ.Elem(), typ.Elem())
+					g.WriteString(" /* redacted */")
+				} else {
+					g.write(val.MapIndex(key), typ.Elem(), true)
+				}
+				g.WriteByte(',')
+				g.newline()
+			}
+			g.pop()
+		}
+		g.WriteByte('}')
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if val.IsNil() {
+			g.writeNil(typ, ctx)
+			return
+		}
+		g.fail("%v values have no literal", kind)
+	default:
+		g.writeBasic(val, ctx)
+	}
+}
+
+// writeNil writes a nil value of type typ.
+func (g *goWriter) writeNil(typ, ctx reflect.Type) {
+	if ctx == typ {
+		g.WriteString("nil")
+		return
+	}
+	fmt.Fprintf(g, "(%s)(nil)", g.typeName(typ))
+}
+
+// writeZero writes the zero value of type typ.
+func (g *goWriter) writeZero(typ, ctx reflect.Type) {
+	switch typ.Kind() {
+	case reflect.Struct, reflect.Array:
+		g.WriteString(g.typeName(typ))
+		g.WriteString("{}")
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		g.writeNil(typ, ctx)
+	default:
+		g.writeBasic(reflect.Zero(typ), ctx)
+	}
+}
+
+// defaultTypes are the types which untyped constants of each kind assume.
+var defaultTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:       reflect.TypeOf(false),
+	reflect.Int:        reflect.TypeOf(0),
+	reflect.Float64:    reflect.TypeOf(0.0),
+	reflect.Complex128: reflect.TypeOf(0i),
+	reflect.String:     reflect.TypeOf(""),
+}
+
+// writeBasic writes a value of a basic kind as a constant, converted to its
+// type unless that is implied by ctx.
+func (g *goWriter) writeBasic(val reflect.Value, ctx reflect.Type) {
+	var lit string
+	switch kind := val.Kind(); kind {
+	case reflect.Bool:
+		lit = strconv.FormatBool(val.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		lit = strconv.FormatInt(val.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		lit = strconv.FormatUint(val.Uint(), 10)
+	case reflect.Uintptr:
+		lit = fmt.Sprintf("0x%x", val.Uint())
+	case reflect.Float32, reflect.Float64:
+		lit = goFloat(val.Float(), val.Type().Bits())
+	case reflect.Complex64, reflect.Complex128:
+		c, bits := val.Complex(), val.Type().Bits()/2
+		lit = fmt.Sprintf("complex(%s, %s)", goFloat(real(c), bits), goFloat(imag(c), bits))
+	case reflect.String:
+		lit = goString(val.String())
+	default:
+		g.fail("%v values have no literal", kind)
+		return
+	}
+	if strings.HasPrefix(lit, "math.") {
+		g.imports["math"] = true
+	}
+
+	typ := val.Type()
+	if ctx == typ || (ctx == nil || ctx.Kind() == reflect.Interface) && defaultTypes[typ.Kind()] == typ {
+		g.WriteString(lit)
+		return
+	}
+	fmt.Fprintf(g, "%s(%s)", g.typeName(typ), lit)
+}
+
+// goFloat returns f as a floating-point constant.
+func goFloat(f float64, bits int) string {
+	switch {
+	case math.IsNaN(f):
+		return "math.NaN()"
+	case math.IsInf(f, 1):
+		return "math.Inf(1)"
+	case math.IsInf(f, -1):
+		return "math.Inf(-1)"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, bits)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// goString returns s as a string literal, preferring a raw string literal for
+// strings which contain quotes, backslashes, or newlines.
+func goString(s string) string {
+	if !strings.ContainsAny(s, "\"\\\n") || !utf8.ValidString(s) || strings.ContainsAny(s, "`\r") {
+		return strconv.Quote(s)
+	}
+	for _, r := range s {
+		if r != '\n' && r != '\t' && !strconv.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return "`" + s + "`"
+}
+
+// writeTime writes t as a call to time.Date.
+func (g *goWriter) writeTime(t time.Time) {
+	g.imports["time"] = true
+	var loc string
+	switch name, offset := t.Zone(); {
+	case t.Location() == time.UTC:
+		loc = "time.UTC"
+	case t.Location() == time.Local:
+		loc = "time.Local"
+	default:
+		loc = fmt.Sprintf("time.FixedZone(%q, %d)", name, offset)
+	}
+	fmt.Fprintf(g, "time.Date(%d, time.%s, %d, %d, %d, %d, %d, %s)",
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// qualifiedIdent matches a qualified identifier in the type arguments of the
+// name of a generic type, which reflect writes with the import path of its
+// package, as in "Box[net/netip.Addr]".
+var qualifiedIdent = regexp.MustCompile(`([\w\-~./]+)\.([\pL_][\pL\pN_]*)`)
+
+// majorVersion matches the last element of an import path which is a major
+// version suffix rather than the package name, as in "math/rand/v2".
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// typeArgs returns the type arguments of a generic type, as written by reflect,
+// as they would be written in Go.  The package names of packages other than
+// GoPackage are only known for the standard library, which names its packages
+// after their import paths.
+func (g *goWriter) typeArgs(args string) string {
+	return qualifiedIdent.ReplaceAllStringFunc(args, func(ident string) string {
+		m := qualifiedIdent.FindStringSubmatch(ident)
+		path, name := m[1], m[2]
+		if path == g.GoPackage {
+			return name
+		}
+		elems := strings.Split(path, "/")
+		if strings.Contains(elems[0], ".") {
+			g.fail("the package name of %s in type arguments %s is unknown", path, args)
+			return ident
+		}
+		pkg := elems[len(elems)-1]
+		if majorVersion.MatchString(pkg) && len(elems) > 1 {
+			pkg = elems[len(elems)-2]
+		}
+		g.imports[path] = true
+		return pkg + "." + name
+	})
+}
+
+// typeName returns the name of typ as it would be written in Go, qualified by
+// its package name unless it is in GoPackage.
+func (g *goWriter) typeName(typ reflect.Type) string {
+	if name := typ.Name(); name != "" {
+		if i := strings.IndexByte(name, '['); i >= 0 {
+			name = name[:i] + g.typeArgs(name[i:])
+		}
+		pkg := typ.PkgPath()
+		if pkg == "" || pkg == g.GoPackage {
+			return name
+		}
+		g.imports[pkg] = true
+		qualified := typ.String()
+		return qualified[:strings.Index(qualified, ".")+1] + name
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return "*" + g.typeName(typ.Elem())
+	case reflect.Slice:
+		return "[]" + g.typeName(typ.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", typ.Len(), g.typeName(typ.Elem()))
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", g.typeName(typ.Key()), g.typeName(typ.Elem()))
+	case reflect.Chan:
+		switch typ.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + g.typeName(typ.Elem())
+		case reflect.SendDir:
+			return "chan<- " + g.typeName(typ.Elem())
+		}
+		return "chan " + g.typeName(typ.Elem())
+	case reflect.Struct:
+		var fields []string
+		for i := 0; i < typ.NumField(); i++ {
+			sf := typ.Field(i)
+			field := sf.Name + " " + g.typeName(sf.Type)
+			if sf.Anonymous {
+				field = g.typeName(sf.Type)
+			}
+			if sf.Tag != "" {
+				field += " " + strconv.Quote(string(sf.Tag))
+			}
+			fields = append(fields, field)
+		}
+		if len(fields) == 0 {
+			return "struct{}"
+		}
+		return "struct{ " + strings.Join(fields, "; ") + " }"
+	case reflect.Interface:
+		if typ.NumMethod() == 0 {
+			return "interface{}"
+		}
+	}
+	return typ.String()
+}
@@ -0,0 +1,309 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	gotoken "go/token"
+	"go/types"
+	"math"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type shape interface{ Area() float64 }
+
+type label string
+
+type box[T any] struct{ V T }
+
+type circle struct{ Radius float64 }
+
+func (c circle) Area() float64 { return math.Pi * c.Radius * c.Radius }
+
+type drawing struct {
+	Title  string
+	Shapes []shape
+	Origin *location
+	Scale  *float64
+	Layers map[string][]int
+	Extra  interface{}
+}
+
+func TestGoSyntax(t *testing.T) {
+	scale := 2.0
+	gocfg := &Config{GoSyntax: true, GoPackage: "github.com/kylelemons/godebug/pretty"}
+	compact := &Config{GoSyntax: true, Compact: true, GoPackage: gocfg.GoPackage}
+
+	tests := []struct {
+		desc    string
+		cfg     *Config
+		val     interface{}
+		want    string
+		imports []string
+	}{
+		{
+			desc: "basic",
+			cfg:  compact,
+			val:  []interface{}{1, int32(2), 3.0, float32(4.5), "five", true, nil, uint8(6), uintptr(7), 8 + 9i},
+			want: `[]interface{}{1, int32(2), 3.0, float32(4.5), "five", true, nil, uint8(6), uintptr(0x7), complex(8.0, 9.0)}`,
+		},
+		{
+			desc: "typed context",
+			cfg:  compact,
+			val:  []struct{ A []uint }{{[]uint{1}}},
+			want: `[]struct{ A []uint }{{A: []uint{1}}}`,
+		},
+		{
+			desc: "typed fields",
+			cfg:  compact,
+			val:  drawing{Title: "t", Extra: float32(1)},
+			want: `drawing{Title: "t", Shapes: nil, Origin: nil, Scale: nil, Layers: nil, Extra: float32(1.0)}`,
+		},
+		{
+			desc: "struct",
+			cfg:  gocfg,
+			val: drawing{
+				Title:  "sketch",
+				Shapes: []shape{circle{1}, nil},
+				Origin: &location{1, 2},
+				Scale:  &scale,
+				Layers: map[string][]int{"b": {2}, "a": nil},
+			},
+			want: strings.Trim(`
+drawing{
+	Title: "sketch",
+	Shapes: []shape{
+		circle{
+			Radius: 1.0,
+		},
+		nil,
+	},
+	Origin: &location{
+		X: 1,
+		Y: 2,
+	},
+	Scale: func() *float64 { var v float64 = 2.0; return &v }(),
+	Layers: map[string][]int{
+		"a": nil,
+		"b": {
+			2,
+		},
+	},
+	Extra: nil,
+}
+			`, "\n\t"),
+		},
+		{
+			desc: "elided pointers",
+			cfg:  compact,
+			val:  []*location{{1, 2}, nil},
+			want: `[]*location{{X: 1, Y: 2}, nil}`,
+		},
+		{
+			desc: "typed nils",
+			cfg:  compact,
+			val:  []interface{}{(*location)(nil), []int(nil), map[int]int(nil), (func())(nil)},
+			want: `[]interface{}{(*location)(nil), ([]int)(nil), (map[int]int)(nil), (func())(nil)}`,
+		},
+		{
+			desc:    "qualified names",
+			cfg:     compact,
+			val:     []interface{}{net.IPv4Mask(255, 0, 0, 0), time.Duration(5)},
+			want:    `[]interface{}{net.IPMask{255, 0, 0, 0}, time.Duration(5)}`,
+			imports: []string{"net", "time"},
+		},
+		{
+			desc:    "type arguments",
+			cfg:     compact,
+			val:     []interface{}{box[time.Duration]{5}, box[[]label]{}, box[map[string]*box[int]]{}},
+			want:    `[]interface{}{box[time.Duration]{V: 5}, box[[]label]{V: nil}, box[map[string]*box[int]]{V: nil}}`,
+			imports: []string{"time"},
+		},
+		{
+			desc:    "time",
+			cfg:     compact,
+			val:     time.Date(2009, 2, 13, 23, 31, 30, 5, time.UTC),
+			want:    `time.Date(2009, time.February, 13, 23, 31, 30, 5, time.UTC)`,
+			imports: []string{"time"},
+		},
+		{
+			desc:    "floats",
+			cfg:     compact,
+			val:     []float64{1e21, math.Inf(-1), math.NaN()},
+			want:    `[]float64{1e+21, math.Inf(-1), math.NaN()}`,
+			imports: []string{"math"},
+		},
+		{
+			desc: "strings",
+			cfg:  compact,
+			val:  []string{"plain", `say "hi"`, "two\nlines", "tick`", "\x00"},
+			want: "[]string{\"plain\", `say \"hi\"`, `two\nlines`, \"tick`\", \"\\x00\"}",
+		},
+		{
+			desc: "redaction",
+			cfg:  &Config{GoSyntax: true, Compact: true, GoPackage: gocfg.GoPackage, RedactNames: []string{"password"}},
+			val: []interface{}{
+				account{User: "ford", Password: "towel"},
+				request{Password: "tea", Auth: "xyzzy"},
+			},
+			want: `[]interface{}{account{User: "ford", Home: location{X: 0, Y: 0}, Aliases: nil}, ` +
+				`request{User: "", APIToken: "", Auth: "" /* redacted */, Headers: nil}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, imports, err := test.cfg.SprintGo(test.val)
+			if err != nil {
+				t.Fatalf("SprintGo: %s", err)
+			}
+			if want := test.want; got != want {
+				t.Errorf("SprintGo:\ngot:\n%s\nwant:\n%s", got, want)
+			}
+			if got, want := imports, test.imports; !reflect.DeepEqual(got, want) {
+				t.Errorf("imports = %q, want %q", got, want)
+			}
+			if _, err := parser.ParseExpr(got); err != nil {
+				t.Errorf("ParseExpr: %s", err)
+			}
+			if got, want := test.cfg.Sprint(test.val), test.want; got != want {
+				t.Errorf("Sprint:\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestGoSyntaxTypeCheck(t *testing.T) {
+	cfg := &Config{GoSyntax: true, Compact: true, GoPackage: "github.com/kylelemons/godebug/pretty"}
+	ptr := func(v interface{}) interface{} {
+		p := reflect.New(reflect.TypeOf(v))
+		p.Elem().Set(reflect.ValueOf(v))
+		return p.Interface()
+	}
+	var boxed interface{} = 3
+
+	tests := []struct {
+		desc string
+		val  interface{}
+		typ  string
+	}{
+		{desc: "int", val: ptr(3), typ: "*int"},
+		{desc: "int64", val: ptr(int64(3)), typ: "*int64"},
+		{desc: "float32", val: ptr(float32(1.5)), typ: "*float32"},
+		{desc: "named string", val: ptr(label("north")), typ: "*label"},
+		{desc: "qualified", val: ptr(time.Duration(5)), typ: "*time.Duration"},
+		{desc: "interface", val: &boxed, typ: "*interface{}"},
+		{desc: "time", val: ptr(time.Date(2009, 2, 13, 23, 31, 30, 5, time.UTC)), typ: "*time.Time"},
+		{desc: "fields", val: drawing{Scale: ptr(2.0).(*float64), Extra: ptr(uint8(4))}, typ: "drawing"},
+		{desc: "type arguments", val: box[*box[time.Duration]]{&box[time.Duration]{5}}, typ: "box[*box[time.Duration]]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			expr, imports, err := cfg.SprintGo(test.val)
+			if err != nil {
+				t.Fatalf("SprintGo: %s", err)
+			}
+			src := new(bytes.Buffer)
+			fmt.Fprintln(src, "package pretty")
+			for _, imp := range imports {
+				fmt.Fprintf(src, "import %q\n", imp)
+			}
+			fmt.Fprintln(src, "type label string")
+			fmt.Fprintln(src, "type box[T any] struct{ V T }")
+			fmt.Fprintln(src, "type location struct{ X, Y int }")
+			fmt.Fprintln(src, "type shape interface{ Area() float64 }")
+			fmt.Fprintln(src, "type drawing struct {")
+			fmt.Fprintln(src, "\tTitle string; Shapes []shape; Origin *location; Scale *float64")
+			fmt.Fprintln(src, "\tLayers map[string][]int; Extra interface{}")
+			fmt.Fprintln(src, "}")
+			fmt.Fprintf(src, "var _ %s = %s\n", test.typ, expr)
+
+			fset := gotoken.NewFileSet()
+			file, err := parser.ParseFile(fset, "gen.go", src, 0)
+			if err != nil {
+				t.Fatalf("ParseFile: %s\n%s", err, src)
+			}
+			conf := types.Config{Importer: importer.Default()}
+			if _, err := conf.Check("pretty", fset, []*ast.File{file}, nil); err != nil {
+				t.Errorf("Check: %s\n%s", err, src)
+			}
+		})
+	}
+}
+
+func TestGoSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		desc string
+		cfg  *Config
+		val  interface{}
+		err  string
+	}{
+		{
+			desc: "func",
+			val:  struct{ F func() }{func() {}},
+			err:  "pretty: cannot write Go syntax: func values have no literal at .F",
+		},
+		{
+			desc: "chan",
+			val:  []chan int{make(chan int)},
+			err:  "pretty: cannot write Go syntax: chan values have no literal at [0]",
+		},
+		{
+			desc: "cycle",
+			val:  circular(2),
+			err:  "pretty: cannot write Go syntax: *pretty.ListNode refers to itself at .Next.Next",
+		},
+		{
+			desc: "unexported field",
+			cfg:  &Config{GoSyntax: true, IncludeUnexported: true},
+			val:  []strings.Builder{{}},
+			err:  "pretty: cannot write Go syntax: strings.Builder has unexported field addr at [0].addr",
+		},
+		{
+			desc: "type argument package",
+			val:  box[label]{},
+			err: "pretty: cannot write Go syntax: the package name of github.com/kylelemons/godebug/pretty " +
+				"in type arguments [github.com/kylelemons/godebug/pretty.label] is unknown",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cfg := test.cfg
+			if cfg == nil {
+				cfg = &Config{GoSyntax: true}
+			}
+			if _, _, err := cfg.SprintGo(test.val); err == nil || err.Error() != test.err {
+				t.Errorf("SprintGo error = %v, want %q", err, test.err)
+			}
+			if got, want := cfg.Sprint(test.val), "<"+test.err+">"; got != want {
+				t.Errorf("Sprint = %q, want %q", got, want)
+			}
+			buf := new(bytes.Buffer)
+			if _, err := cfg.Fprint(buf, test.val); err == nil || buf.Len() > 0 {
+				t.Errorf("Fprint = %v, wrote %q; want error and no output", err, buf.String())
+			}
+		})
+	}
+}
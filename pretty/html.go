@@ -0,0 +1,181 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>pretty</title>
+<style>
+.pretty { font-family: monospace; margin: 1em 0; }
+.pretty div:not(.fields), .pretty summary { white-space: pre; }
+.pretty .fields { padding-left: 2ch; }
+.pretty summary { cursor: pointer; }
+.pretty details:not([open]) > summary::after { content: "\2026" attr(data-close); }
+.pretty div[title]:hover, .pretty summary:hover { background: #eee; }
+.pretty .key { color: #0e7c86; }
+.pretty .string { color: #217a21; }
+.pretty .number { color: #a05a00; }
+.pretty .nil { color: #888; }
+.pretty .bool { color: #8a2be2; }
+.pretty .cycle { color: #c0392b; }
+</style>
+</head>
+<body>
+`
+
+const htmlFooter = `</body>
+</html>
+`
+
+// htmlClasses holds the CSS classes of the parts of HTML output.
+var htmlClasses = &Theme{
+	Key:    "key",
+	String: "string",
+	Number: "number",
+	Nil:    "nil",
+	Bool:   "bool",
+	Cycle:  "cycle",
+}
+
+// FprintHTML writes an HTML document showing the given values to w according
+// to the DefaultConfig.  See Config.FprintHTML for details.
+func FprintHTML(w io.Writer, vals ...interface{}) (n int64, err error) {
+	return DefaultConfig.FprintHTML(w, vals...)
+}
+
+// FprintHTML writes an HTML document showing the given values to w according
+// to cfg, such as for a debug page served over HTTP.  The values are laid out
+// as with Diffable, except that each struct, map, slice, and array can be
+// collapsed and expanded, and that the type of each value is shown when the
+// mouse is over it.  Values which refer to themselves, when TrackCycles is
+// set, are marked as usual, and each reference to them links to them.
+//
+// The options of cfg which affect layout and types, such as Compact and Types,
+// do not apply.  If cfg is not valid (see Validate), nothing is written and
+// the error is returned.
+func (cfg *Config) FprintHTML(w io.Writer, vals ...interface{}) (n int64, err error) {
+	if err := cfg.Validate(); err != nil {
+		return 0, err
+	}
+	typed := *cfg
+	typed.Types = AllTypes
+	ref := typed.newReflector()
+
+	buf := new(bytes.Buffer)
+	f := newFormatter(cfg, buf)
+	f.WriteString(htmlHeader)
+	for _, val := range vals {
+		f.WriteString("<div class=\"pretty\">\n")
+		f.writeHTML(ref.val2node(reflect.ValueOf(val)), "", "")
+		f.WriteString("</div>\n")
+	}
+	f.WriteString(htmlFooter)
+	f.Flush()
+	return buf.WriteTo(w)
+}
+
+// writeHTML writes the HTML for n, with the given HTML before and after it on
+// its first and last lines.  Structs, maps, slices, and arrays with elements
+// are written as details elements, and anything else is written on one line.
+func (f *formatter) writeHTML(n Node, before, after string) {
+	var attrs, marker string
+	typed, compact := false, false
+	for unwrapped := false; !unwrapped; {
+		switch w := n.(type) {
+		case Typed:
+			// A pointer's type is shown rather than that of what it points to.
+			if !typed {
+				attrs += ` title="` + html.EscapeString(w.Type) + `"`
+				typed = true
+			}
+			n = w.Value
+		case Target:
+			tag := f.tagFor(w.ID)
+			attrs += fmt.Sprintf(` id="pretty-%d"`, tag)
+			marker = fmt.Sprintf(`<span class="%s">&lt;#%d&gt;</span> `, htmlClasses.Cycle, tag)
+			n = w.Value
+		case CompactVal:
+			compact = true
+			n = w.Value
+		default:
+			unwrapped = true
+		}
+	}
+
+	var open, close string
+	switch n := n.(type) {
+	case KeyVals:
+		if len(n) > 0 && !compact {
+			open, close = "{", "}"
+		}
+	case List:
+		if len(n) > 0 && !compact {
+			open, close = "[", "]"
+		}
+	}
+	if open == "" {
+		fmt.Fprintf(f, "<div%s>%s%s%s%s</div>\n", attrs, before, marker, f.htmlValue(n), after)
+		return
+	}
+
+	fmt.Fprintf(f, "<details open%s>\n", attrs)
+	fmt.Fprintf(f, "<summary data-close=\"%s%s\">%s%s%s</summary>\n", close, after, before, marker, open)
+	f.WriteString("<div class=\"fields\">\n")
+	switch n := n.(type) {
+	case KeyVals:
+		for _, kv := range n {
+			key := fmt.Sprintf(`<span class="%s">%s</span>: `, htmlClasses.Key, html.EscapeString(kv.Key))
+			f.writeHTML(kv.Val, key, ",")
+		}
+	case List:
+		for _, elem := range n {
+			f.writeHTML(elem, "", ",")
+		}
+	}
+	f.WriteString("</div>\n")
+	fmt.Fprintf(f, "<div>%s%s</div>\n", close, after)
+	f.WriteString("</details>\n")
+}
+
+// htmlValue returns the HTML for n, which is written on one line.
+func (f *formatter) htmlValue(n Node) string {
+	span := func(class, text string) string {
+		if class == "" {
+			return html.EscapeString(text)
+		}
+		return fmt.Sprintf(`<span class="%s">%s</span>`, class, html.EscapeString(text))
+	}
+	switch n := n.(type) {
+	case StringVal:
+		return span(htmlClasses.String, strconv.Quote(string(n)))
+	case RawVal:
+		return span(htmlClasses.rawColor(string(n)), string(n))
+	case Ref:
+		tag := f.tagFor(n.ID)
+		return fmt.Sprintf(`<a class="%s" href="#pretty-%d">&lt;see #%d&gt;</a>`, htmlClasses.Cycle, tag, tag)
+	}
+	return html.EscapeString(f.compactString(stripTypes(n)))
+}
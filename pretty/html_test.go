@@ -0,0 +1,143 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFprintHTML(t *testing.T) {
+	type page struct {
+		Title  string
+		Views  int
+		Tags   []string
+		Origin location `pretty:",compact"`
+		Labels map[location]interface{}
+		Token  string `pretty:",redact"`
+	}
+
+	tests := []struct {
+		desc string
+		cfg  *Config
+		vals []interface{}
+		want string
+	}{
+		{
+			desc: "scalars",
+			cfg:  DefaultConfig,
+			vals: []interface{}{"<b>&", nil, true},
+			want: `
+<div class="pretty">
+<div title="string"><span class="string">&#34;&lt;b&gt;&amp;&#34;</span></div>
+</div>
+<div class="pretty">
+<div><span class="nil">nil</span></div>
+</div>
+<div class="pretty">
+<div title="bool"><span class="bool">true</span></div>
+</div>
+`,
+		},
+		{
+			desc: "collapsible",
+			cfg:  DefaultConfig,
+			vals: []interface{}{page{
+				Title:  "home",
+				Tags:   []string{"a"},
+				Labels: map[location]interface{}{{1, 2}: 3.5},
+			}},
+			want: `
+<div class="pretty">
+<details open title="pretty.page">
+<summary data-close="}">{</summary>
+<div class="fields">
+<div title="string"><span class="key">Title</span>: <span class="string">&#34;home&#34;</span>,</div>
+<div title="int"><span class="key">Views</span>: <span class="number">0</span>,</div>
+<details open title="[]string">
+<summary data-close="],"><span class="key">Tags</span>: [</summary>
+<div class="fields">
+<div title="string"><span class="string">&#34;a&#34;</span>,</div>
+</div>
+<div>],</div>
+</details>
+<div title="pretty.location"><span class="key">Origin</span>: {X:0,Y:0},</div>
+<details open title="map[pretty.location]interface {}">
+<summary data-close="},"><span class="key">Labels</span>: {</summary>
+<div class="fields">
+<div title="float64"><span class="key">{X:1,Y:2}</span>: <span class="number">3.5</span>,</div>
+</div>
+<div>},</div>
+</details>
+<div><span class="key">Token</span>: &lt;redacted&gt;,</div>
+</div>
+<div>}</div>
+</details>
+</div>
+`,
+		},
+		{
+			desc: "cycles",
+			cfg:  CycleTracker,
+			vals: []interface{}{circular(1)},
+			want: `
+<div class="pretty">
+<details open title="*pretty.ListNode" id="pretty-1">
+<summary data-close="}"><span class="cycle">&lt;#1&gt;</span> {</summary>
+<div class="fields">
+<div title="int"><span class="key">Value</span>: <span class="number">1</span>,</div>
+<div title="*pretty.ListNode"><span class="key">Next</span>: <a class="cycle" href="#pretty-1">&lt;see #1&gt;</a>,</div>
+</div>
+<div>}</div>
+</details>
+</div>
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if _, err := test.cfg.FprintHTML(buf, test.vals...); err != nil {
+				t.Fatalf("FprintHTML: %s", err)
+			}
+			got := buf.String()
+			if !strings.HasPrefix(got, htmlHeader) || !strings.HasSuffix(got, htmlFooter) {
+				t.Fatalf("FprintHTML did not write a complete document:\n%s", got)
+			}
+			got = strings.TrimSuffix(strings.TrimPrefix(got, htmlHeader), htmlFooter)
+			if want := strings.TrimPrefix(test.want, "\n"); got != want {
+				t.Errorf("FprintHTML:\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestFprintHTMLInvalid(t *testing.T) {
+	cfg := &Config{
+		Formatter: map[reflect.Type]interface{}{
+			reflect.TypeOf(0): func(string) string { return "" },
+		},
+	}
+	buf := new(bytes.Buffer)
+	if _, err := cfg.FprintHTML(buf, 1); err == nil {
+		t.Errorf("FprintHTML with an invalid Formatter succeeded")
+	}
+	if buf.Len() > 0 {
+		t.Errorf("FprintHTML with an invalid Formatter wrote:\n%s", buf)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonIndent is the indentation added for each level of nesting in JSON.
+const jsonIndent = "  "
+
+// writeJSON writes n as JSON at the given level of indentation.
+//
+// Targets, Typed nodes, and CompactVals are unwrapped, and the members they
+// imply are added to the object for the value they wrap, or to an object which
+// holds the value as its "$value" member if it isn't one.
+func (f *formatter) writeJSON(n Node, indent string) {
+	var meta KeyVals
+	for unwrapped := false; !unwrapped; {
+		switch w := n.(type) {
+		case Target:
+			meta = append(meta, KeyVal{"$id", StringVal(fmt.Sprintf("#%d", f.tagFor(w.ID)))})
+			n = w.Value
+		case Typed:
+			meta = append(meta, KeyVal{"$type", StringVal(w.Type)})
+			n = w.Value
+		case CompactVal:
+			cfg := *f.Config
+			cfg.Compact = true
+			saved := f.Config
+			f.Config = &cfg
+			defer func() { f.Config = saved }()
+			n = w.Value
+		default:
+			unwrapped = true
+		}
+	}
+
+	switch n := n.(type) {
+	case KeyVals:
+		f.writeJSONObject(append(meta, n...), indent)
+	case List:
+		if len(meta) > 0 {
+			f.writeJSONObject(append(meta, KeyVal{"$value", n}), indent)
+			return
+		}
+		f.WriteByte('[')
+		for i, elem := range n {
+			if i > 0 {
+				f.WriteByte(',')
+			}
+			f.jsonNewline(indent + jsonIndent)
+			f.writeJSON(elem, indent+jsonIndent)
+		}
+		if len(n) > 0 {
+			f.jsonNewline(indent)
+		}
+		f.WriteByte(']')
+	case Ref:
+		f.writeJSONObject(KeyVals{{"$ref", StringVal(fmt.Sprintf("#%d", f.tagFor(n.ID)))}}, indent)
+	default:
+		if len(meta) > 0 {
+			f.writeJSONObject(append(meta, KeyVal{"$value", n}), indent)
+			return
+		}
+		f.WriteString(jsonScalar(n))
+	}
+}
+
+// writeJSONObject writes kvs as a JSON object.
+func (f *formatter) writeJSONObject(kvs KeyVals, indent string) {
+	f.WriteByte('{')
+	for i, kv := range kvs {
+		if i > 0 {
+			f.WriteByte(',')
+		}
+		f.jsonNewline(indent + jsonIndent)
+		f.WriteString(jsonQuote(kv.Key))
+		f.WriteByte(':')
+		if !f.Compact {
+			f.WriteByte(' ')
+		}
+		f.writeJSON(kv.Val, indent+jsonIndent)
+	}
+	if len(kvs) > 0 {
+		f.jsonNewline(indent)
+	}
+	f.WriteByte('}')
+}
+
+// jsonNewline starts a new line at the given indentation, unless the output
+// is compact.
+func (f *formatter) jsonNewline(indent string) {
+	if f.Compact {
+		return
+	}
+	f.WriteByte('\n')
+	f.WriteString(indent)
+}
+
+// jsonScalar returns the JSON for a StringVal or a RawVal.  Raw values which
+// are valid JSON numbers or booleans are written as such, nil is written as
+// null, and anything else, such as the address of a func, is written as a
+// string.
+func jsonScalar(n Node) string {
+	switch n := n.(type) {
+	case StringVal:
+		return jsonQuote(string(n))
+	case RawVal:
+		s := string(n)
+		if s == "nil" {
+			return "null"
+		}
+		if s == "true" || s == "false" {
+			return s
+		}
+		if s != "" && strings.IndexByte("-0123456789", s[0]) >= 0 && json.Valid([]byte(s)) {
+			return s
+		}
+		return jsonQuote(s)
+	}
+	return jsonQuote(new(formatter).compactString(n))
+}
+
+// jsonQuote returns s as a JSON string.  Unlike json.Marshal, it leaves
+// characters which are special in HTML, such as those in "<redacted>", as-is.
+func jsonQuote(s string) string {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s) // a string can always be encoded
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,161 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	type entry struct {
+		Name  string
+		count int
+		Score float64
+		Token string `pretty:",redact"`
+		Tags  []string
+		Extra interface{}
+	}
+
+	tests := []struct {
+		desc string
+		cfg  Config
+		val  interface{}
+		want string
+	}{
+		{
+			desc: "scalars",
+			cfg:  Config{JSON: true},
+			val:  []interface{}{nil, true, 42, -1.5, math.NaN(), uintptr(0xff), "<a>\n"},
+			want: strings.Trim(`
+[
+  null,
+  true,
+  42,
+  -1.5,
+  "NaN",
+  "0xFF",
+  "<a>\n"
+]
+			`, "\n\t"),
+		},
+		{
+			desc: "struct",
+			cfg:  Config{JSON: true, IncludeUnexported: true},
+			val: entry{
+				Name:  "ford",
+				count: 2,
+				Score: 0.5,
+				Token: "secret",
+				Tags:  []string{"hoopy"},
+				Extra: map[string]int{},
+			},
+			want: strings.Trim(`
+{
+  "Name": "ford",
+  "count": 2,
+  "Score": 0.5,
+  "Token": "<redacted>",
+  "Tags": [
+    "hoopy"
+  ],
+  "Extra": {}
+}
+			`, "\n\t"),
+		},
+		{
+			desc: "compact",
+			cfg:  Config{JSON: true, Compact: true},
+			val:  entry{Name: "ford", Tags: []string{}},
+			want: `{"Name":"ford","Score":0,"Token":"<redacted>","Tags":[],"Extra":null}`,
+		},
+		{
+			desc: "non-string keys",
+			cfg:  Config{JSON: true, Compact: true},
+			val:  map[location]int{{2, 1}: 3, {1, 2}: 4},
+			want: `{"{X:1,Y:2}":4,"{X:2,Y:1}":3}`,
+		},
+		{
+			desc: "formatters print strings",
+			cfg: Config{
+				JSON:    true,
+				Compact: true,
+				Formatter: map[reflect.Type]interface{}{
+					reflect.TypeOf(location{}): func(l location) string { return "42" },
+				},
+			},
+			val:  []location{{1, 2}},
+			want: `["42"]`,
+		},
+		{
+			desc: "filters",
+			cfg: Config{
+				JSON:        true,
+				Compact:     true,
+				IgnorePaths: []string{"Score", "Extra"},
+			},
+			val:  entry{Name: "ford"},
+			want: `{"Name":"ford","Token":"<redacted>","Tags":[]}`,
+		},
+		{
+			desc: "types",
+			cfg:  Config{JSON: true, Compact: true, Types: InterfaceTypes},
+			val:  []interface{}{location{1, 2}, int64(3), nil},
+			want: `[{"$type":"pretty.location","X":1,"Y":2},{"$type":"int64","$value":3},null]`,
+		},
+		{
+			desc: "cycles",
+			cfg:  Config{JSON: true, TrackCycles: true},
+			val:  circular(2),
+			want: strings.Trim(`
+{
+  "$id": "#1",
+  "Value": 1,
+  "Next": {
+    "Value": 2,
+    "Next": {
+      "$ref": "#1"
+    }
+  }
+}
+			`, "\n\t"),
+		},
+		{
+			desc: "cyclic list",
+			cfg:  Config{JSON: true, Compact: true, TrackCycles: true},
+			val: func() interface{} {
+				l := []interface{}{1, nil}
+				l[1] = &l
+				return &l
+			}(),
+			want: `{"$id":"#1","$value":[1,{"$ref":"#1"}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got := test.cfg.Sprint(test.val)
+			if want := test.want; got != want {
+				t.Errorf("Sprint:\ngot:\n%s\nwant:\n%s", got, want)
+			}
+			if !json.Valid([]byte(got)) {
+				t.Errorf("Sprint returned invalid JSON:\n%s", got)
+			}
+		})
+	}
+}
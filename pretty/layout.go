@@ -0,0 +1,203 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A layout is a node prepared for printing within Width.  Layouts are built
+// in one pass over the tree, which measures each node on one line, and printed
+// in another, which breaks a struct, map, slice, or array over several lines
+// only if it doesn't fit on one.  Both passes take linear time.
+type layout struct {
+	node  Node // the node, which is printed as with Compact if it fits
+	width int  // the width of node on one line
+
+	// Groups, which are KeyVals and Lists with elements, may be broken.
+	wrappers []Node    // the Targets and Typed nodes around the group
+	prefix   int       // the width of the text for wrappers before the group
+	open     string    // the bracket before the elements
+	close    string    // the bracket after them, and the text for wrappers
+	keys     []string  // the keys of the elements, for KeyVals
+	elems    []*layout // the elements
+}
+
+// newLayout returns the layout for n.  Its tags are numbered in the order in
+// which they are printed.
+func (f *formatter) newLayout(n Node) *layout {
+	l := &layout{node: n}
+
+	inner := n
+	for unwrapped := false; !unwrapped; {
+		switch w := inner.(type) {
+		case Target:
+			l.wrappers = append(l.wrappers, w)
+			l.prefix += len(fmt.Sprintf("<#%d> ", f.tagFor(w.ID)))
+			inner = w.Value
+		case Typed:
+			l.wrappers = append(l.wrappers, w)
+			l.prefix += len(w.Type)
+			if !isGroup(w.Value) {
+				// A conversion, which wraps a Target.
+				l.prefix += len("(")
+				l.close += ")"
+			}
+			inner = w.Value
+		default:
+			unwrapped = true
+		}
+	}
+
+	switch inner := inner.(type) {
+	case KeyVals:
+		l.open, l.close = "{", "}"+l.close
+		for _, kv := range inner {
+			l.keys = append(l.keys, kv.Key)
+			l.elems = append(l.elems, f.newLayout(kv.Val))
+		}
+	case List:
+		l.open, l.close = "[", "]"+l.close
+		for _, elem := range inner {
+			l.elems = append(l.elems, f.newLayout(elem))
+		}
+	}
+	if len(l.elems) == 0 {
+		l.wrappers, l.prefix, l.close = nil, 0, ""
+		l.width = f.flatWidth(n)
+		return l
+	}
+
+	l.width = l.prefix + len(l.open) + len(l.elems) - 1 + len(l.close)
+	for i, elem := range l.elems {
+		if l.keys != nil {
+			l.width += len(l.keys[i]) + len(":")
+		}
+		l.width += elem.width
+	}
+	return l
+}
+
+// isGroup returns whether n is a KeyVals or a List.
+func isGroup(n Node) bool {
+	switch n.(type) {
+	case KeyVals, List:
+		return true
+	}
+	return false
+}
+
+// flatWidth returns the width of n on one line, as with Compact.
+func (f *formatter) flatWidth(n Node) int {
+	switch n := n.(type) {
+	case StringVal:
+		return len(strconv.Quote(string(n)))
+	case RawVal:
+		return len(n)
+	case Ref:
+		return len(fmt.Sprintf("<see #%d>", f.tagFor(n.ID)))
+	case Target:
+		return len(fmt.Sprintf("<#%d> ", f.tagFor(n.ID))) + f.flatWidth(n.Value)
+	case Typed:
+		if isGroup(n.Value) {
+			return len(n.Type) + f.flatWidth(n.Value)
+		}
+		return len(n.Type) + len("()") + f.flatWidth(n.Value)
+	case CompactVal:
+		return f.flatWidth(n.Value)
+	case KeyVals:
+		width := len("{}")
+		for i, kv := range n {
+			if i > 0 {
+				width += len(",")
+			}
+			width += len(kv.Key) + len(":") + f.flatWidth(kv.Val)
+		}
+		return width
+	case List:
+		width := len("[]")
+		for i, elem := range n {
+			if i > 0 {
+				width += len(",")
+			}
+			width += f.flatWidth(elem)
+		}
+		return width
+	}
+	return len(f.compactString(n))
+}
+
+// writeLayout writes l, which starts at column col and is followed on its last
+// line by trail more columns of text.
+func (f *formatter) writeLayout(l *layout, col, trail int) {
+	if len(l.elems) == 0 || col+l.width+trail <= f.Width {
+		f.writeCompact(l.node)
+		return
+	}
+
+	for _, w := range l.wrappers {
+		switch w := w.(type) {
+		case Target:
+			f.paint(f.theme.Cycle, fmt.Sprintf("<#%d>", f.tagFor(w.ID)))
+			f.WriteByte(' ')
+		case Typed:
+			f.paint(f.theme.Type, w.Type)
+			if !isGroup(w.Value) {
+				f.WriteByte('(')
+			}
+		}
+	}
+	f.WriteString(l.open)
+
+	// The elements are laid out as in the default layout.
+	inner := col + l.prefix + len(l.open)
+	keyWidth := 0
+	for _, key := range l.keys {
+		if len(key) > keyWidth {
+			keyWidth = len(key)
+		}
+	}
+	for i, elem := range l.elems {
+		if i > 0 {
+			f.WriteString(",\n")
+			f.WriteString(strings.Repeat(" ", inner))
+		}
+		elemCol := inner
+		if l.keys != nil {
+			f.paint(f.theme.Key, l.keys[i])
+			f.WriteString(": ")
+			f.WriteString(strings.Repeat(" ", keyWidth-len(l.keys[i])))
+			elemCol += keyWidth + len(": ")
+		}
+		elemTrail := len(",")
+		if i == len(l.elems)-1 {
+			elemTrail = len(l.close) + trail
+		}
+		f.writeLayout(elem, elemCol, elemTrail)
+	}
+	f.WriteString(l.close)
+}
+
+// writeCompact writes n on one line.
+func (f *formatter) writeCompact(n Node) {
+	cfg := *f.Config
+	cfg.Compact, cfg.ShortList, cfg.ShortStruct, cfg.Width = true, 0, 0, 0
+	saved := f.Config
+	f.Config = &cfg
+	defer func() { f.Config = saved }()
+	n.format(f, "")
+}
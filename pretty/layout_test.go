@@ -0,0 +1,131 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"strings"
+	"testing"
+)
+
+type team struct {
+	Name    string
+	Members []string
+	Home    location
+}
+
+func TestWidth(t *testing.T) {
+	val := []team{
+		{"red", []string{"ford", "arthur"}, location{1, 2}},
+		{"blue", nil, location{3, 4}},
+	}
+
+	tests := []struct {
+		desc  string
+		width int
+		want  string
+	}{
+		{
+			desc:  "fits",
+			width: 100,
+			want: `
+[{Name:"red",Members:["ford","arthur"],Home:{X:1,Y:2}},{Name:"blue",Members:[],Home:{X:3,Y:4}}]`,
+		},
+		{
+			desc:  "elements fit",
+			width: 60,
+			want: `
+[{Name:"red",Members:["ford","arthur"],Home:{X:1,Y:2}},
+ {Name:"blue",Members:[],Home:{X:3,Y:4}}]`,
+		},
+		{
+			// The first element would fit, but not with the comma after it.
+			desc:  "trailing comma",
+			width: 54,
+			want: `
+[{Name:    "red",
+  Members: ["ford","arthur"],
+  Home:    {X:1,Y:2}},
+ {Name:"blue",Members:[],Home:{X:3,Y:4}}]`,
+		},
+		{
+			// The last element would fit, but not with the brackets after it.
+			desc:  "trailing brackets",
+			width: 40,
+			want: `
+[{Name:    "red",
+  Members: ["ford","arthur"],
+  Home:    {X:1,Y:2}},
+ {Name:    "blue",
+  Members: [],
+  Home:    {X:3,Y:4}}]`,
+		},
+		{
+			desc:  "nothing fits",
+			width: 1,
+			want: `
+[{Name:    "red",
+  Members: ["ford",
+            "arthur"],
+  Home:    {X: 1,
+            Y: 2}},
+ {Name:    "blue",
+  Members: [],
+  Home:    {X: 3,
+            Y: 4}}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cfg := &Config{Width: test.width}
+			got := cfg.Sprint(val)
+			if want := strings.TrimPrefix(test.want, "\n"); got != want {
+				t.Errorf("Sprint:\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestWidthMatchesLayouts(t *testing.T) {
+	cyclic := circular(2)
+	vals := []struct {
+		desc string
+		val  interface{}
+	}{
+		{"struct", team{"red", []string{"ford"}, location{1, 2}}},
+		{"map", map[string]interface{}{"a": []int{1, 2}, "bb": nil}},
+		{"interfaces", []interface{}{location{1, 2}, int8(3), "x"}},
+		{"cycles", cyclic},
+		{"tags", account{User: "zaphod", Home: location{1, 2}}},
+	}
+
+	for _, types := range []TypeMode{NoTypes, InterfaceTypes, AllTypes} {
+		for _, test := range vals {
+			t.Run(test.desc, func(t *testing.T) {
+				normal := &Config{TrackCycles: true, Types: types}
+				compact := &Config{TrackCycles: true, Types: types, Compact: true}
+				narrow := &Config{TrackCycles: true, Types: types, Width: 1}
+				wide := &Config{TrackCycles: true, Types: types, Width: 1000}
+
+				if got, want := narrow.Sprint(test.val), normal.Sprint(test.val); got != want {
+					t.Errorf("Width 1 (types %d):\ngot:\n%s\nwant:\n%s", types, got, want)
+				}
+				if got, want := wide.Sprint(test.val), compact.Sprint(test.val); got != want {
+					t.Errorf("Width 1000 (types %d):\ngot:  %s\nwant: %s", types, got, want)
+				}
+			})
+		}
+	}
+}
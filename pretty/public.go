@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"os"
 	"reflect"
 	"time"
 
@@ -27,8 +28,8 @@ import (
 
 // A Config represents optional configuration parameters for formatting.
 //
-// Some options, notably ShortList, dramatically increase the overhead
-// of pretty-printing a value.
+// Some options, notably ShortList and ShortStruct, dramatically increase the
+// overhead of pretty-printing a value.
 type Config struct {
 	// Verbosity options
 	Compact  bool // One-line output. Overrides Diffable.
@@ -39,24 +40,133 @@ type Config struct {
 	PrintStringers      bool // Call String on a fmt.Stringer
 	PrintTextMarshalers bool // Call MarshalText on an encoding.TextMarshaler
 	SkipZeroFields      bool // Skip struct fields that have a zero value.
+	StrictTypes         bool // Values of different types are never equal.
+
+	// Types selects which values are annotated with their types, such as
+	// values held in interfaces, whose types may vary.  Annotated values are
+	// printed like conversions, as in int64(3), or like composite literals, as
+	// in circle.Circle{Radius: 2}.  Map keys and the values pointed to by
+	// pointers are never annotated, since their types are implied.
+	Types TypeMode
 
 	// Output transforms
-	ShortList int // Maximum character length for short lists if nonzero.
+	ShortList   int // Maximum character length for short lists if nonzero.
+	ShortStruct int // Likewise for structs and maps, unless Diffable.
+
+	// Width, if nonzero, is the maximum length of the lines of the default
+	// layout.  Each struct, map, slice, and array is printed on one line, as
+	// with Compact, if it fits within Width along with the text which follows
+	// it on the line, and is otherwise broken over several lines as usual.
+	// Values which don't fit even on lines of their own extend past Width.
+	// Unlike ShortList and ShortStruct, which it overrides, this takes time
+	// linear in the size of the output.  Width is ignored if Compact or
+	// Diffable is set.
+	Width int
+
+	// Colors
+	//
+	// Color selects when the output is colored, using the colors of Theme or,
+	// if Theme is nil, of DefaultTheme.  With AutoColor, output is colored when
+	// Print or Fprint write it to a terminal, unless the NO_COLOR environment
+	// variable is set or the output is Diffable, so that colors never end up in
+	// output meant for comparisons.  With AlwaysColor, all output is colored,
+	// including that of Sprint and Compare.  Output in GoSyntax, JSON, or YAML
+	// is never colored.
+	Color ColorMode
+	Theme *Theme
+
+	// Go syntax
+	//
+	// If GoSyntax is enabled, values are printed as Go expressions which can
+	// be pasted into a program, such as the expected values of a test.  The
+	// output is formatted by gofmt, on one line if Compact is also enabled
+	// (except where gofmt requires more, such as in anonymous struct types).
+	// Composite values are printed as composite literals with type names,
+	// pointers to them as &T{...}, strings as quoted or raw string literals,
+	// and time.Time values as calls to time.Date.  Formatters, Stringers, and
+	// TextMarshalers are not used, but the field filters, struct tags, and
+	// redaction apply, with redacted values printed as zero values.
+	//
+	// Type names are qualified by the names of their packages, except for
+	// those in the package with the import path GoPackage.  Use SprintGo to
+	// find out which packages need to be imported.  Type arguments of generic
+	// types may only refer to the standard library and GoPackage, since the
+	// names of other packages cannot be told from their import paths.
+	//
+	// Values which cannot be written in Go, such as funcs, chans, values
+	// which refer to themselves, and unexported fields (see IncludeUnexported)
+	// outside of GoPackage, cause Fprint to return an error.  Other functions
+	// print a description of the problem instead.
+	GoSyntax  bool
+	GoPackage string
+
+	// JSON
+	//
+	// If JSON is enabled, values are printed as JSON, on one line if Compact is
+	// also enabled and indented otherwise, so that they can be processed by
+	// tools like jq.  Structs and maps are printed as objects, with map entries
+	// in the usual order and non-string keys in their compact form, and slices
+	// and arrays as arrays.  Numbers and booleans are printed as such, nil as
+	// null, and other values, including the output of Formatters, Stringers,
+	// and TextMarshalers, as strings.  The field filters, struct tags, and
+	// redaction apply as usual.
+	//
+	// With TrackCycles, a value which refers to itself gets an "$id" member,
+	// such as "#1", and references to it are printed as {"$ref": "#1"}.  Values
+	// annotated with their types (see Types) get a "$type" member.  Values
+	// which aren't objects are wrapped in one as its "$value" member when they
+	// need either.
+	//
+	// GoSyntax takes precedence over JSON.
+	JSON bool
+
+	// YAML
+	//
+	// If YAML is enabled, values are printed as a YAML document, in block style
+	// unless Compact is also enabled, in which case they are printed in flow
+	// style on one line.  Values are printed as with JSON, except that strings
+	// are only quoted where necessary, strings of several lines are printed as
+	// literal blocks, values which refer to themselves are given anchors such as
+	// &1 which references to them use as aliases such as *1, and values
+	// annotated with their types are given tags such as !pretty.Point.
+	// Fields tagged `pretty:",compact"` are printed in flow style.
+	//
+	// GoSyntax and JSON take precedence over YAML.
+	YAML bool
 
 	// Type-specific overrides
 	//
 	// Formatter maps a type to a function that will provide a one-line string
-	// representation of the input value.  Conceptually:
+	// representation of the input value, or a substitute value to be printed
+	// in its place.  Conceptually:
 	//   Formatter[reflect.TypeOf(v)](v) = "v as a string"
+	//   Formatter[reflect.TypeOf(v)](v) = map[string]interface{}{"v": "as a map"}
 	//
 	// Note that the first argument need not explicitly match the type, it must
 	// merely be callable with it.
 	//
+	// The RegisterFormatter and RegisterSubstitute functions provide a
+	// type-safe way to add entries, and Validate checks the entries which have
+	// been added directly.
+	//
 	// When processing an input value, if its type exists as a key in Formatter:
 	//   1) If the value is nil, no stringification is performed.
 	//      This allows overriding of PrintStringers and PrintTextMarshalers.
 	//   2) The value will be called with the input as its only argument.
-	//      The function must return a string as its first return value.
+	//   3) If the first return value is a string, it is printed as-is.
+	//      Otherwise, it is printed in place of the input value with the usual
+	//      layout.  The same entry is not consulted again for the substitute
+	//      itself, such as a normalized copy of the input, for the value it
+	//      points to, or for copies of the input within it, but other values
+	//      within it are formatted as usual.  A substitute which is a Node is
+	//      printed as-is.
+	//
+	// A key which is an interface type applies to every type that implements
+	// the interface, such as any concrete error type for the error entry in
+	// DefaultFormatter.  An entry for the value's own type takes precedence,
+	// and otherwise the interfaces are tried in order of their names, as given
+	// by reflect.Type.String.  Either way, a Formatter takes precedence over
+	// PrintStringers and PrintTextMarshalers.
 	//
 	// In addition to func literals, two common values for this will be:
 	//   fmt.Sprint        (function) func Sprint(...interface{}) string
@@ -67,6 +177,38 @@ type Config struct {
 	// use a function that calls .String on the formal value parameter.
 	Formatter map[reflect.Type]interface{}
 
+	// Field filters
+	//
+	// These leave values out of the output of Print and out of comparisons,
+	// such as timestamps and IDs which would otherwise make every comparison
+	// fail.
+	//
+	// IgnorePaths lists patterns for the paths of struct fields and map
+	// entries to leave out.  Paths are written as by Path.String, though the
+	// leading dot may be omitted, and each pattern must match the whole path
+	// from the top-level value.  In patterns, a "*" step matches any number of
+	// steps and "[*]" matches any single list index or map key:
+	//   "*.CreatedAt"      the CreatedAt field of any struct
+	//   "Items[*].id"      the id field of each element of the Items field
+	//   `Labels["owner"]`  the "owner" entry of the Labels map
+	IgnorePaths []string
+
+	// IgnoreFields maps a struct type to the names of its fields to leave out.
+	IgnoreFields map[reflect.Type][]string
+
+	// Ignore, if non-nil, is called for each struct field and map entry with
+	// its path and value, and returns whether to leave it out.
+	Ignore func(path Path, val reflect.Value) bool
+
+	// RedactNames lists patterns for the names of struct fields and the string
+	// keys of map entries whose values are secrets, such as "*password*" or
+	// "*token".  Their values are printed as a placeholder and left out of
+	// comparisons, as are the values of fields tagged `pretty:",redact"` and
+	// Redactable values, though a Redactable value which is redacted differs
+	// from one which is not.  Patterns are matched as by path.Match, ignoring
+	// case.
+	RedactNames []string
+
 	// If TrackCycles is enabled, pretty will detect and track
 	// self-referential structures. If a self-referential structure (aka a
 	// "recursive" value) is detected, numbered placeholders will be emitted.
@@ -75,6 +217,16 @@ type Config struct {
 	TrackCycles bool
 }
 
+// A TypeMode selects which values are annotated with their types.
+type TypeMode int
+
+// Type annotation modes.
+const (
+	NoTypes        TypeMode = iota // No values are annotated.
+	InterfaceTypes                 // Values held in interfaces are annotated.
+	AllTypes                       // All values are annotated.
+)
+
 // Default Config objects
 var (
 	// DefaultFormatter is the default set of overrides for stringification.
@@ -104,19 +256,45 @@ var (
 	}
 )
 
-func (cfg *Config) fprint(buf *bytes.Buffer, vals ...interface{}) {
+// newReflector returns a reflector for converting values to nodes under cfg.
+func (cfg *Config) newReflector() *reflector {
 	ref := &reflector{
 		Config: cfg,
 	}
 	if cfg.TrackCycles {
 		ref.pointerTracker = new(pointerTracker)
 	}
+	for _, pat := range cfg.IgnorePaths {
+		ref.ignore = append(ref.ignore, parsePattern(pat))
+	}
+	return ref
+}
+
+// fprint writes the representations of vals to buf.  It returns the first
+// error encountered, after writing a description of it in place of the value.
+func (cfg *Config) fprint(buf *bytes.Buffer, theme *Theme, vals ...interface{}) error {
+	var firstErr error
+	ref := cfg.newReflector()
 	for i, val := range vals {
 		if i > 0 {
 			buf.WriteByte('\n')
 		}
-		newFormatter(cfg, buf).write(ref.val2node(reflect.ValueOf(val)))
+		if cfg.GoSyntax {
+			expr, _, err := cfg.SprintGo(val)
+			if err != nil {
+				expr = "<" + err.Error() + ">"
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+			buf.WriteString(expr)
+			continue
+		}
+		f := newFormatter(cfg, buf)
+		f.theme = theme
+		f.write(ref.val2node(reflect.ValueOf(val)))
 	}
+	return firstErr
 }
 
 // Print writes the DefaultConfig representation of the given values to standard output.
@@ -126,7 +304,9 @@ func Print(vals ...interface{}) {
 
 // Print writes the configured presentation of the given values to standard output.
 func (cfg *Config) Print(vals ...interface{}) {
-	fmt.Println(cfg.Sprint(vals...))
+	buf := new(bytes.Buffer)
+	cfg.fprint(buf, cfg.colors(os.Stdout), vals...)
+	fmt.Println(buf.String())
 }
 
 // Sprint returns a string representation of the given value according to the DefaultConfig.
@@ -137,7 +317,7 @@ func Sprint(vals ...interface{}) string {
 // Sprint returns a string representation of the given value according to cfg.
 func (cfg *Config) Sprint(vals ...interface{}) string {
 	buf := new(bytes.Buffer)
-	cfg.fprint(buf, vals...)
+	cfg.fprint(buf, cfg.colors(nil), vals...)
 	return buf.String()
 }
 
@@ -147,9 +327,16 @@ func Fprint(w io.Writer, vals ...interface{}) (n int64, err error) {
 }
 
 // Fprint writes the representation of the given value to the writer according to the cfg.
+// If cfg is not valid (see Validate), or if GoSyntax is set and a value cannot be
+// written in Go, nothing is written and the error is returned.
 func (cfg *Config) Fprint(w io.Writer, vals ...interface{}) (n int64, err error) {
+	if err := cfg.Validate(); err != nil {
+		return 0, err
+	}
 	buf := new(bytes.Buffer)
-	cfg.fprint(buf, vals...)
+	if err := cfg.fprint(buf, cfg.colors(w), vals...); err != nil {
+		return 0, err
+	}
 	return buf.WriteTo(w)
 }
 
@@ -180,9 +367,34 @@ func Compare(a, b interface{}) string {
 // The comparison is based on the intentionally-untyped output of Print, and as
 // such this comparison is pretty forviving.  In particular, if the types of or
 // types within in a and b are different but have the same representation,
-// Compare will not indicate any differences between them.
+// Compare will not indicate any differences between them, unless StrictTypes
+// is set.  With StrictTypes, values whose types differ are annotated with their
+// types, and only those values, so that the mismatch shows up in the diff
+// without cluttering the rest of the output.  For a comparison
+// which matches up the fields, elements and map entries of a and b, see Diff
+// and CompareStructure.  To check only whether a and b are equal, see Equal.
 func (cfg *Config) Compare(a, b interface{}) string {
 	diffCfg := *cfg
 	diffCfg.Diffable = true
-	return diff.Diff(cfg.Sprint(a), cfg.Sprint(b))
+	if !cfg.StrictTypes {
+		return diff.Diff(cfg.Sprint(a), cfg.Sprint(b))
+	}
+
+	// Annotate the values whose types differ, so they show up in the diff.
+	typed := make(map[string]bool)
+	for _, d := range cfg.Differences(a, b) {
+		if d.Kind == TypeMismatch {
+			typed[d.Path.String()] = true
+		}
+	}
+	sprint := func(val interface{}) string {
+		ref := cfg.newReflector()
+		ref.typed = typed
+		buf := new(bytes.Buffer)
+		f := newFormatter(cfg, buf)
+		f.theme = cfg.colors(nil)
+		f.write(ref.val2node(reflect.ValueOf(val)))
+		return buf.String()
+	}
+	return diff.Diff(sprint(a), sprint(b))
 }
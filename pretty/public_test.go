@@ -72,6 +72,52 @@ func TestDiff(t *testing.T) {
 	}
 }
 
+func TestCompareStrictTypes(t *testing.T) {
+	type pair struct {
+		Name  string
+		Value interface{}
+	}
+	a := []pair{{"x", int32(1)}, {"y", 2}}
+	b := []pair{{"x", int64(1)}, {"y", 2}}
+
+	if got := CompareConfig.Compare(a, b); got != "" {
+		t.Errorf("Compare without StrictTypes = %q, want no differences", got)
+	}
+
+	cfg := *CompareConfig
+	cfg.StrictTypes = true
+	want := ` [
+  {
+   Name: "x",
+-  Value: int32(1),
++  Value: int64(1),
+  },
+  {
+   Name: "y",
+   Value: 2,
+  },
+ ]`
+	if got := cfg.Compare(a, b); got != want {
+		t.Errorf("Compare with StrictTypes:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The type of a pointer implies the type of the value it points to.
+	type origin struct{ X int }
+	type offset struct{ X int }
+	pa := pair{"p", &origin{1}}
+	pb := pair{"p", &offset{1}}
+	want = ` {
+  Name: "p",
+- Value: *pretty.origin{
++ Value: *pretty.offset{
+   X: 1,
+  },
+ }`
+	if got := cfg.Compare(pa, pb); got != want {
+		t.Errorf("Compare pointers with StrictTypes:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
 func TestSkipZeroFields(t *testing.T) {
 	type example struct {
 		Name    string
@@ -0,0 +1,109 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"path"
+	"reflect"
+	"strings"
+)
+
+// redacted is the placeholder printed in place of a redacted value.
+const redacted = RawVal("<redacted>")
+
+// A Redactable value knows whether it holds a secret, such as a password or a
+// token, which must never be printed.  Redactable values for which Redacted
+// returns true are printed as a placeholder regardless of the Config, and
+// before any Formatter, String method, or MarshalText method is consulted.
+type Redactable interface {
+	Redacted() bool
+}
+
+var redactableType = reflect.TypeOf((*Redactable)(nil)).Elem()
+
+// redacts returns whether val is a Redactable value which should be redacted.
+//
+// Values found in unexported fields can't be interfaced to call Redacted, so
+// it is called on a copy of them if they are of a basic kind, and otherwise
+// they are redacted to be safe.
+func redacts(val reflect.Value) bool {
+	if !val.IsValid() || !val.Type().Implements(redactableType) {
+		return false
+	}
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if val.IsNil() {
+			return false
+		}
+	}
+	if !val.CanInterface() {
+		if val.Kind() == reflect.Interface {
+			return redacts(val.Elem())
+		}
+		cp, ok := basicCopy(val)
+		if !ok {
+			return true
+		}
+		val = cp
+	}
+	return val.Interface().(Redactable).Redacted()
+}
+
+// basicCopy returns a copy of val which can be interfaced, if val is of a
+// basic kind.
+func basicCopy(val reflect.Value) (reflect.Value, bool) {
+	cp := reflect.New(val.Type()).Elem()
+	switch val.Kind() {
+	case reflect.Bool:
+		cp.SetBool(val.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		cp.SetInt(val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		cp.SetUint(val.Uint())
+	case reflect.Float32, reflect.Float64:
+		cp.SetFloat(val.Float())
+	case reflect.Complex64, reflect.Complex128:
+		cp.SetComplex(val.Complex())
+	case reflect.String:
+		cp.SetString(val.String())
+	default:
+		return reflect.Value{}, false
+	}
+	return cp, true
+}
+
+// redactsName returns whether the value of a struct field or map entry with the
+// given name is redacted by RedactNames.
+func (cfg *Config) redactsName(name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range cfg.RedactNames {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// redactsField returns whether the value of the struct field sf is redacted by
+// its tag or its name.
+func (cfg *Config) redactsField(sf reflect.StructField) bool {
+	return parseTag(sf).redact || cfg.redactsName(sf.Name)
+}
+
+// redactsKey returns whether the value of the map entry with the given key is
+// redacted by its name.
+func (cfg *Config) redactsKey(key reflect.Value) bool {
+	return key.Kind() == reflect.String && cfg.redactsName(key.String())
+}
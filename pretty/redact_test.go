@@ -0,0 +1,179 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// token is a secret unless it is empty.
+type token string
+
+func (t token) Redacted() bool { return t != "" }
+func (t token) String() string { return "token:" + string(t) }
+
+// session has a pointer method, so only *session is Redactable.
+type session struct{ ID string }
+
+func (s *session) Redacted() bool { return true }
+
+type request struct {
+	User     string
+	Password string
+	APIToken string
+	Auth     token
+	Headers  map[string]string
+}
+
+func TestRedaction(t *testing.T) {
+	req := request{
+		User:     "arthur",
+		Password: "tea",
+		APIToken: "42",
+		Auth:     "xyzzy",
+		Headers:  map[string]string{"Accept": "*/*", "X-Auth-Token": "abc"},
+	}
+
+	tests := []struct {
+		desc string
+		cfg  *Config
+		val  interface{}
+		want string
+	}{
+		{
+			desc: "marker",
+			cfg:  &Config{Compact: true},
+			val:  req,
+			want: `{User:"arthur",Password:"tea",APIToken:"42",Auth:<redacted>,Headers:{Accept:"*/*",X-Auth-Token:"abc"}}`,
+		},
+		{
+			desc: "names",
+			cfg:  &Config{Compact: true, RedactNames: []string{"PASSWORD", "*token"}},
+			val:  req,
+			want: `{User:"arthur",Password:<redacted>,APIToken:<redacted>,Auth:<redacted>,Headers:{Accept:"*/*",X-Auth-Token:<redacted>}}`,
+		},
+		{
+			desc: "marker before stringer",
+			cfg:  &Config{Compact: true, PrintStringers: true},
+			val:  []token{"", "xyzzy"},
+			want: `["token:",<redacted>]`,
+		},
+		{
+			desc: "marker before formatter",
+			cfg: &Config{Compact: true, Formatter: map[reflect.Type]interface{}{
+				reflect.TypeOf(token("")): func(t token) string { return string(t) },
+			}},
+			val:  []token{"xyzzy"},
+			want: `[<redacted>]`,
+		},
+		{
+			desc: "pointer method",
+			cfg:  &Config{Compact: true},
+			val:  []interface{}{session{"a"}, &session{"b"}, (*session)(nil)},
+			want: `[{ID:"a"},<redacted>,nil]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if got, want := test.cfg.Sprint(test.val), test.want; got != want {
+				t.Errorf("Sprint:\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestRedactionUnexported(t *testing.T) {
+	type hidden struct {
+		auth    token
+		empty   token
+		session *session
+		secret  Redactable
+	}
+	val := hidden{auth: "hunter2", session: &session{"a"}, secret: token("xyzzy")}
+
+	tests := []struct {
+		desc string
+		cfg  *Config
+		want string
+	}{
+		{
+			desc: "print",
+			cfg:  &Config{Compact: true, IncludeUnexported: true},
+			want: `{auth:<redacted>,empty:"",session:<redacted>,secret:<redacted>}`,
+		},
+		{
+			desc: "JSON",
+			cfg:  &Config{Compact: true, IncludeUnexported: true, JSON: true},
+			want: `{"auth":"<redacted>","empty":"","session":"<redacted>","secret":"<redacted>"}`,
+		},
+		{
+			desc: "YAML",
+			cfg:  &Config{Compact: true, IncludeUnexported: true, YAML: true},
+			want: `{auth: <redacted>, empty: "", session: <redacted>, secret: <redacted>}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if got, want := test.cfg.Sprint(val), test.want; got != want {
+				t.Errorf("Sprint:\ngot:  %s\nwant: %s", got, want)
+			}
+		})
+	}
+
+	if diff := Compare(val, hidden{}); strings.Contains(diff, "hunter2") || strings.Contains(diff, "xyzzy") {
+		t.Errorf("Compare shows the secret:\n%s", diff)
+	}
+}
+
+func TestRedactionCompare(t *testing.T) {
+	cfg := &Config{RedactNames: []string{"password", "*token"}}
+	a := request{User: "arthur", Password: "tea", Auth: "xyzzy", Headers: map[string]string{"X-Auth-Token": "abc"}}
+	b := request{User: "arthur", Password: "coffee", Auth: "plugh", Headers: map[string]string{}}
+
+	if diff := cfg.Diff(a, b); diff != "" {
+		t.Errorf("Diff of redacted values:\n%s", diff)
+	}
+	if !cfg.Equal(a, b) {
+		t.Errorf("Equal of redacted values = false, want true")
+	}
+
+	b.User = "ford"
+	if got, want := cfg.Diff(a, b), `.User: "arthur" -> "ford"`; got != want {
+		t.Errorf("Diff = %q, want %q", got, want)
+	}
+}
+
+func TestRedactionCompareOneSide(t *testing.T) {
+	a := request{User: "arthur", Auth: "xyzzy"}
+	b := request{User: "arthur", Auth: ""}
+
+	if cfg := CompareConfig; cfg.Equal(a, b) {
+		t.Errorf("Equal with one side redacted = true, want false\nCompare:\n%s", cfg.Compare(a, b))
+	}
+	var got []string
+	for _, d := range Differences(a, b) {
+		got = append(got, d.Path.String()+" "+d.Kind.String())
+	}
+	if diff := Compare(got, []string{".Auth changed"}); diff != "" {
+		t.Errorf("Differences: (-got +want)\n%s", diff)
+	}
+	if got, want := Diff(a, b), `.Auth: <redacted> -> ""`; got != want {
+		t.Errorf("Diff = %q, want %q", got, want)
+	}
+}
@@ -85,11 +85,31 @@ func (p *pointerTracker) id(ptr uintptr) (int, bool) {
 type reflector struct {
 	*Config
 	*pointerTracker
+
+	path    Path            // path from the top-level value to the current one
+	typed   map[string]bool // paths at which values are annotated with types
+	ignore  []pathPattern   // parsed IgnorePaths
+	untyped bool            // whether the next value is already annotated
+
+	interfaces []reflect.Type         // interface keys of Formatter, once sorted
+	sorted     bool                   // whether interfaces has been populated
+	checked    map[reflect.Type]error // results of checkFormatter by value type
+
+	substituting []substitution // values whose substitutes are being converted
 }
 
+// substitution is a value whose substitute is being converted, along with the
+// substitute if it is a pointer, such as to a normalized copy of the value.
+type substitution struct {
+	val, ptr reflect.Value
+}
+
+func (r *reflector) push(step PathStep) { r.path = append(r.path, step) }
+func (r *reflector) pop()               { r.path = r.path[:len(r.path)-1] }
+
 // follow handles following a possiblly-recursive reference to the given value
 // from the given ptr address.
-func (r *reflector) follow(ptr uintptr, val reflect.Value) node {
+func (r *reflector) follow(ptr uintptr, val reflect.Value) Node {
 	if r.pointerTracker == nil {
 		// Tracking disabled
 		return r.val2node(val)
@@ -98,7 +118,7 @@ func (r *reflector) follow(ptr uintptr, val reflect.Value) node {
 	// If a parent already followed this, emit a reference marker
 	if r.seen(ptr) {
 		id := r.keep(ptr)
-		return ref{id}
+		return Ref{id}
 	}
 
 	// Track the pointer we're following while on this recursive branch
@@ -108,77 +128,289 @@ func (r *reflector) follow(ptr uintptr, val reflect.Value) node {
 
 	// If the recursion used this ptr, wrap it with a target marker
 	if id, ok := r.id(ptr); ok {
-		return target{id, n}
+		return Target{id, n}
 	}
 
 	// Otherwise, return the node unadulterated
 	return n
 }
 
-func (r *reflector) val2node(val reflect.Value) (ret node) {
-	if !val.IsValid() {
-		return rawVal("nil")
+// override returns the node for val if its representation is overridden by a
+// Formatter, a String method, or a MarshalText method.
+func (r *reflector) override(val reflect.Value) (ret Node, ok bool) {
+	if !val.CanInterface() {
+		return nil, false
 	}
 
-	if val.CanInterface() {
-		// Detect panics in calling functions on nil pointers.
-		//
-		// We still want to call them, as it's possible that a nil value is
-		// valid for the particular type.
-		//
-		// If we detect a panic, just return raw nil.
-		if val.Kind() == reflect.Ptr && val.IsNil() {
-			defer func() {
-				if r := recover(); r != nil {
-					ret = rawVal("nil")
-				}
-			}()
-		}
-
-		v := val.Interface()
-		if formatter, ok := r.Formatter[val.Type()]; ok {
-			if formatter != nil {
-				res := reflect.ValueOf(formatter).Call([]reflect.Value{val})
-				return rawVal(res[0].Interface().(string))
+	// Detect panics in calling functions on nil pointers.
+	//
+	// We still want to call them, as it's possible that a nil value is
+	// valid for the particular type.
+	//
+	// If we detect a panic, just return raw nil.
+	if val.Kind() == reflect.Ptr && val.IsNil() {
+		defer func() {
+			if r := recover(); r != nil {
+				ret, ok = RawVal("nil"), true
 			}
-		} else {
-			if s, ok := v.(fmt.Stringer); ok && r.PrintStringers {
-				return stringVal(s.String())
+		}()
+	}
+
+	v := val.Interface()
+	if key, formatter, ok := r.formatter(val.Type()); ok {
+		if formatter != nil && !r.substituted(val) {
+			if err := r.checkFormatter(val.Type(), formatter); err != nil {
+				return RawVal("<" + err.Error() + ">"), true
 			}
-			if t, ok := v.(encoding.TextMarshaler); ok && r.PrintTextMarshalers {
-				if raw, err := t.MarshalText(); err == nil { // if NOT an error
-					return stringVal(string(raw))
+			res := reflect.ValueOf(formatter).Call([]reflect.Value{val})[0]
+			if res.Kind() == reflect.String {
+				if r.JSON || r.YAML {
+					return StringVal(res.String()), true
 				}
+				return RawVal(res.String()), true
+			}
+			return r.substitute(key, val, res), true
+		}
+		return nil, false
+	}
+	if s, ok := v.(fmt.Stringer); ok && r.PrintStringers {
+		return StringVal(s.String()), true
+	}
+	if t, ok := v.(encoding.TextMarshaler); ok && r.PrintTextMarshalers {
+		if raw, err := t.MarshalText(); err == nil { // if NOT an error
+			return StringVal(string(raw)), true
+		}
+	}
+	return nil, false
+}
+
+// formatter returns the key and value of the Formatter entry which applies to
+// values of type typ.  An entry for typ itself takes precedence over entries
+// for the interfaces which typ implements, which are considered in order of
+// their names.
+func (r *reflector) formatter(typ reflect.Type) (reflect.Type, interface{}, bool) {
+	if formatter, ok := r.Formatter[typ]; ok {
+		return typ, formatter, true
+	}
+	if !r.sorted {
+		for key := range r.Formatter {
+			if key.Kind() == reflect.Interface {
+				r.interfaces = append(r.interfaces, key)
 			}
 		}
+		sort.Slice(r.interfaces, func(i, j int) bool {
+			return r.interfaces[i].String() < r.interfaces[j].String()
+		})
+		r.sorted = true
+	}
+	for _, iface := range r.interfaces {
+		if typ.Implements(iface) {
+			return iface, r.Formatter[iface], true
+		}
+	}
+	return nil, nil, false
+}
+
+// substitute returns the node for sub, the value returned by the Formatter
+// entry for key in place of val.  If sub is a Node, it is used as-is.  The
+// entry is not consulted again for sub itself, such as a normalized copy of
+// val, for the value sub points to, or for copies of val within sub, as that
+// would never return.  Other values within sub are formatted as usual.
+func (r *reflector) substitute(key reflect.Type, val, sub reflect.Value) Node {
+	if n, ok := sub.Interface().(Node); ok {
+		return n
+	}
+	if sub.Kind() == reflect.Interface && !sub.IsNil() {
+		sub = sub.Elem()
+	}
+
+	s := substitution{val: val}
+	if sub.Kind() == reflect.Ptr && !sub.IsNil() {
+		s.ptr = sub
+	}
+	r.substituting = append(r.substituting, s)
+	defer func() { r.substituting = r.substituting[:len(r.substituting)-1] }()
+
+	if sub.IsValid() {
+		if k, _, ok := r.formatter(sub.Type()); ok && k == key {
+			return r.convert(sub)
+		}
+	}
+	return r.val2node(sub)
+}
+
+// substituted returns whether val is a value whose substitute is being
+// converted, or the value which such a substitute points to.
+func (r *reflector) substituted(val reflect.Value) bool {
+	for _, s := range r.substituting {
+		if p := s.ptr; p.IsValid() && val.CanAddr() && val.Type() == p.Type().Elem() && val.Addr().Pointer() == p.Pointer() {
+			return true
+		}
+		if sameValue(val, s.val) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameValue returns whether a and b, or the values they hold if they are
+// interfaces, are equal values of the same comparable type.
+func sameValue(a, b reflect.Value) (same bool) {
+	for a.Kind() == reflect.Interface && !a.IsNil() {
+		a = a.Elem()
+	}
+	for b.Kind() == reflect.Interface && !b.IsNil() {
+		b = b.Elem()
+	}
+	if a.Type() != b.Type() || !a.Type().Comparable() || !a.CanInterface() || !b.CanInterface() {
+		return false
+	}
+	defer func() {
+		if recover() != nil {
+			same = false // such as for interfaces holding uncomparable values
+		}
+	}()
+	return a.Interface() == b.Interface()
+}
+
+// checkFormatter returns the result of the checkFormatter function, which it
+// caches by the type of the values being formatted.
+func (r *reflector) checkFormatter(typ reflect.Type, format interface{}) error {
+	if err, ok := r.checked[typ]; ok {
+		return err
+	}
+	if r.checked == nil {
+		r.checked = make(map[reflect.Type]error)
+	}
+	err := checkFormatter(typ, format)
+	r.checked[typ] = err
+	return err
+}
+
+// includeField returns whether the field sf of the struct type typ, with value
+// field, should be included in the output.  The path must lead to the field.
+func (r *reflector) includeField(typ reflect.Type, sf reflect.StructField, field reflect.Value) bool {
+	if !r.IncludeUnexported && sf.PkgPath != "" {
+		return false
+	}
+	tag := parseTag(sf)
+	if tag.skip || (r.SkipZeroFields || tag.omitEmpty) && isZeroVal(field) {
+		return false
+	}
+	if r.ignoredField(typ, sf.Name) || r.ignored(field) {
+		return false
+	}
+	return true
+}
+
+// field2node returns the node for the value of the struct field sf.
+func (r *reflector) field2node(sf reflect.StructField, tag fieldTag, field reflect.Value) Node {
+	switch {
+	case r.redactsField(sf):
+		return redacted
+	case tag.compact:
+		return CompactVal{r.val2node(field)}
+	}
+	return r.val2node(field)
+}
+
+func (r *reflector) val2node(val reflect.Value) Node {
+	if !val.IsValid() {
+		return RawVal("nil")
+	}
+
+	// Interfaces are annotated with the type of the value they hold instead.
+	if r.annotates(val) {
+		return Typed{val.Type().String(), r.untypedNode(val)}
 	}
 
+	if redacts(val) {
+		return redacted
+	}
+	if n, ok := r.override(val); ok {
+		return n
+	}
+	return r.convert(val)
+}
+
+// annotates returns whether val, which must be valid, is to be annotated with
+// its type.
+func (r *reflector) annotates(val reflect.Value) bool {
+	if r.untyped {
+		r.untyped = false
+		return false
+	}
+	if val.Kind() == reflect.Interface {
+		return false
+	}
+	if r.Types == AllTypes {
+		return true
+	}
+	return r.typed != nil && r.typed[r.path.String()]
+}
+
+// untypedNode returns the node for val without annotating it with its type,
+// for values whose type is already shown or implied.
+func (r *reflector) untypedNode(val reflect.Value) Node {
+	r.untyped = true
+	defer func() { r.untyped = false }()
+	return r.val2node(val)
+}
+
+// stripTypes returns n without the type annotations added by Types, such as
+// for map keys, whose types are implied by the type of the map.
+func stripTypes(n Node) Node {
+	return Transform(n, func(n Node) Node {
+		if t, ok := n.(Typed); ok {
+			return t.Value
+		}
+		return n
+	})
+}
+
+// convert returns the node for val based on its kind, without considering how
+// its representation might be overridden.
+func (r *reflector) convert(val reflect.Value) Node {
 	switch kind := val.Kind(); kind {
 	case reflect.Ptr:
 		if val.IsNil() {
-			return rawVal("nil")
+			return RawVal("nil")
+		}
+		if r.Types == AllTypes || r.typed[r.path.String()] {
+			// The pointer's type is shown, which implies the type of the value
+			// it points to, found at the same path.
+			r.untyped = true
+			defer func() { r.untyped = false }()
 		}
 		return r.follow(val.Pointer(), val.Elem())
 	case reflect.Interface:
 		if val.IsNil() {
-			return rawVal("nil")
+			return RawVal("nil")
+		}
+		elem := val.Elem()
+		if r.Types == InterfaceTypes {
+			return Typed{elem.Type().String(), r.untypedNode(elem)}
 		}
-		return r.val2node(val.Elem())
+		return r.val2node(elem)
 	case reflect.String:
-		return stringVal(val.String())
+		return StringVal(val.String())
 	case reflect.Slice:
-		n := list{}
+		n := List{}
 		length := val.Len()
 		ptr := val.Pointer()
 		for i := 0; i < length; i++ {
+			r.push(Index(i))
 			n = append(n, r.follow(ptr, val.Index(i)))
+			r.pop()
 		}
 		return n
 	case reflect.Array:
-		n := list{}
+		n := List{}
 		length := val.Len()
 		for i := 0; i < length; i++ {
+			r.push(Index(i))
 			n = append(n, r.val2node(val.Index(i)))
+			r.pop()
 		}
 		return n
 	case reflect.Map:
@@ -187,64 +419,74 @@ func (r *reflector) val2node(val reflect.Value) (ret node) {
 		pairs := make([]mapPair, 0, len(keys))
 		for _, key := range keys {
 			pairs = append(pairs, mapPair{
-				key:   new(formatter).compactString(r.val2node(key)), // can't be cyclic
+				key:   new(formatter).compactString(stripTypes(r.val2node(key))), // can't be cyclic
+				mkey:  key,
 				value: val.MapIndex(key),
 			})
 		}
 		sort.Sort(byKey(pairs))
 
 		// Process the keys into the final representation
-		ptr, n := val.Pointer(), keyvals{}
+		ptr, n := val.Pointer(), KeyVals{}
 		for _, pair := range pairs {
-			n = append(n, keyval{
-				key: pair.key,
-				val: r.follow(ptr, pair.value),
+			r.push(MapKey{pair.mkey, pair.key})
+			if r.ignored(pair.value) {
+				r.pop()
+				continue
+			}
+			var val Node = redacted
+			if !r.redactsKey(pair.mkey) {
+				val = r.follow(ptr, pair.value)
+			}
+			n = append(n, KeyVal{
+				Key: pair.key,
+				Val: val,
 			})
+			r.pop()
 		}
 		return n
 	case reflect.Struct:
-		n := keyvals{}
+		n := KeyVals{}
 		typ := val.Type()
 		fields := typ.NumField()
 		for i := 0; i < fields; i++ {
-			sf := typ.Field(i)
-			if !r.IncludeUnexported && sf.PkgPath != "" {
-				continue
-			}
-			field := val.Field(i)
-			if r.SkipZeroFields && isZeroVal(field) {
-				continue
+			sf, field := typ.Field(i), val.Field(i)
+			r.push(Field(sf.Name))
+			if r.includeField(typ, sf, field) {
+				tag := parseTag(sf)
+				n = append(n, KeyVal{tag.name, r.field2node(sf, tag, field)})
 			}
-			n = append(n, keyval{sf.Name, r.val2node(field)})
+			r.pop()
 		}
 		return n
 	case reflect.Bool:
 		if val.Bool() {
-			return rawVal("true")
+			return RawVal("true")
 		}
-		return rawVal("false")
+		return RawVal("false")
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return rawVal(fmt.Sprintf("%d", val.Int()))
+		return RawVal(fmt.Sprintf("%d", val.Int()))
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return rawVal(fmt.Sprintf("%d", val.Uint()))
+		return RawVal(fmt.Sprintf("%d", val.Uint()))
 	case reflect.Uintptr:
-		return rawVal(fmt.Sprintf("0x%X", val.Uint()))
+		return RawVal(fmt.Sprintf("0x%X", val.Uint()))
 	case reflect.Float32, reflect.Float64:
-		return rawVal(fmt.Sprintf("%v", val.Float()))
+		return RawVal(fmt.Sprintf("%v", val.Float()))
 	case reflect.Complex64, reflect.Complex128:
-		return rawVal(fmt.Sprintf("%v", val.Complex()))
+		return RawVal(fmt.Sprintf("%v", val.Complex()))
 	}
 
 	// Fall back to the default %#v if we can
 	if val.CanInterface() {
-		return rawVal(fmt.Sprintf("%#v", val.Interface()))
+		return RawVal(fmt.Sprintf("%#v", val.Interface()))
 	}
 
-	return rawVal(val.String())
+	return RawVal(val.String())
 }
 
 type mapPair struct {
 	key   string
+	mkey  reflect.Value
 	value reflect.Value
 }
 
@@ -29,37 +29,37 @@ func TestVal2nodeDefault(t *testing.T) {
 	tests := []struct {
 		desc string
 		raw  interface{}
-		want node
+		want Node
 	}{
 		{
 			desc: "nil",
 			raw:  nil,
-			want: rawVal("nil"),
+			want: RawVal("nil"),
 		},
 		{
 			desc: "nil ptr",
 			raw:  (*int)(nil),
-			want: rawVal("nil"),
+			want: RawVal("nil"),
 		},
 		{
 			desc: "nil slice",
 			raw:  []string(nil),
-			want: list{},
+			want: List{},
 		},
 		{
 			desc: "nil map",
 			raw:  map[string]string(nil),
-			want: keyvals{},
+			want: KeyVals{},
 		},
 		{
 			desc: "string",
 			raw:  "zaphod",
-			want: stringVal("zaphod"),
+			want: StringVal("zaphod"),
 		},
 		{
 			desc: "slice",
 			raw:  []string{"a", "b"},
-			want: list{stringVal("a"), stringVal("b")},
+			want: List{StringVal("a"), StringVal("b")},
 		},
 		{
 			desc: "map",
@@ -67,9 +67,9 @@ func TestVal2nodeDefault(t *testing.T) {
 				"zaphod": "beeblebrox",
 				"ford":   "prefect",
 			},
-			want: keyvals{
-				{"ford", stringVal("prefect")},
-				{"zaphod", stringVal("beeblebrox")},
+			want: KeyVals{
+				{"ford", StringVal("prefect")},
+				{"zaphod", StringVal("beeblebrox")},
 			},
 		},
 		{
@@ -79,44 +79,44 @@ func TestVal2nodeDefault(t *testing.T) {
 				[2]int{0, 0}:  "origin",
 				[2]int{1, 3}:  "home",
 			},
-			want: keyvals{
-				{"[-1,2]", stringVal("school")},
-				{"[0,0]", stringVal("origin")},
-				{"[1,3]", stringVal("home")},
+			want: KeyVals{
+				{"[-1,2]", StringVal("school")},
+				{"[0,0]", StringVal("origin")},
+				{"[1,3]", StringVal("home")},
 			},
 		},
 		{
 			desc: "struct",
 			raw:  struct{ Zaphod, Ford string }{"beeblebrox", "prefect"},
-			want: keyvals{
-				{"Zaphod", stringVal("beeblebrox")},
-				{"Ford", stringVal("prefect")},
+			want: KeyVals{
+				{"Zaphod", StringVal("beeblebrox")},
+				{"Ford", StringVal("prefect")},
 			},
 		},
 		{
 			desc: "int",
 			raw:  3,
-			want: rawVal("3"),
+			want: RawVal("3"),
 		},
 		{
 			desc: "time.Time",
 			raw:  time.Unix(1257894000, 0).UTC(),
-			want: rawVal("2009-11-10 23:00:00 +0000 UTC"),
+			want: RawVal("2009-11-10 23:00:00 +0000 UTC"),
 		},
 		{
 			desc: "net.IP",
 			raw:  net.IPv4(127, 0, 0, 1),
-			want: rawVal("127.0.0.1"),
+			want: RawVal("127.0.0.1"),
 		},
 		{
 			desc: "error",
 			raw:  &err,
-			want: rawVal("err"),
+			want: RawVal("err"),
 		},
 		{
 			desc: "nil error",
 			raw:  &errNil,
-			want: rawVal("<nil>"),
+			want: RawVal("<nil>"),
 		},
 	}
 
@@ -135,15 +135,15 @@ func TestVal2node(t *testing.T) {
 		desc string
 		raw  interface{}
 		cfg  *Config
-		want node
+		want Node
 	}{
 		{
 			desc: "struct default",
 			raw:  struct{ Zaphod, Ford, foo string }{"beeblebrox", "prefect", "BAD"},
 			cfg:  DefaultConfig,
-			want: keyvals{
-				{"Zaphod", stringVal("beeblebrox")},
-				{"Ford", stringVal("prefect")},
+			want: KeyVals{
+				{"Zaphod", StringVal("beeblebrox")},
+				{"Ford", StringVal("prefect")},
 			},
 		},
 		{
@@ -152,18 +152,18 @@ func TestVal2node(t *testing.T) {
 			cfg: &Config{
 				IncludeUnexported: true,
 			},
-			want: keyvals{
-				{"Zaphod", stringVal("beeblebrox")},
-				{"Ford", stringVal("prefect")},
-				{"foo", stringVal("GOOD")},
+			want: KeyVals{
+				{"Zaphod", StringVal("beeblebrox")},
+				{"Ford", StringVal("prefect")},
+				{"foo", StringVal("GOOD")},
 			},
 		},
 		{
 			desc: "time default",
 			raw:  struct{ Date time.Time }{time.Unix(1234567890, 0).UTC()},
 			cfg:  DefaultConfig,
-			want: keyvals{
-				{"Date", rawVal("2009-02-13 23:31:30 +0000 UTC")},
+			want: KeyVals{
+				{"Date", RawVal("2009-02-13 23:31:30 +0000 UTC")},
 			},
 		},
 		{
@@ -175,8 +175,8 @@ func TestVal2node(t *testing.T) {
 					reflect.TypeOf(time.Time{}): nil,
 				},
 			},
-			want: keyvals{
-				{"Date", keyvals{}},
+			want: KeyVals{
+				{"Date", KeyVals{}},
 			},
 		},
 		{
@@ -185,8 +185,8 @@ func TestVal2node(t *testing.T) {
 			cfg: &Config{
 				PrintTextMarshalers: true,
 			},
-			want: keyvals{
-				{"Date", stringVal("2009-02-13T23:31:30Z")},
+			want: KeyVals{
+				{"Date", StringVal("2009-02-13T23:31:30Z")},
 			},
 		},
 		{
@@ -195,8 +195,8 @@ func TestVal2node(t *testing.T) {
 			cfg: &Config{
 				PrintStringers: true,
 			},
-			want: keyvals{
-				{"Date", stringVal("2009-02-13 23:31:30 +0000 UTC")},
+			want: KeyVals{
+				{"Date", StringVal("2009-02-13 23:31:30 +0000 UTC")},
 			},
 		},
 		{
@@ -205,8 +205,8 @@ func TestVal2node(t *testing.T) {
 			cfg: &Config{
 				PrintStringers: true,
 			},
-			want: keyvals{
-				{"Date", rawVal("nil")},
+			want: KeyVals{
+				{"Date", RawVal("nil")},
 			},
 		},
 		{
@@ -215,8 +215,8 @@ func TestVal2node(t *testing.T) {
 			cfg: &Config{
 				PrintStringers: true,
 			},
-			want: keyvals{
-				{"V", stringVal("<nil is fine>")},
+			want: KeyVals{
+				{"V", StringVal("<nil is fine>")},
 			},
 		},
 		{
@@ -225,21 +225,21 @@ func TestVal2node(t *testing.T) {
 			cfg: &Config{
 				PrintStringers: true,
 			},
-			want: keyvals{
-				{"V", stringVal("<not nil is fine>")},
+			want: KeyVals{
+				{"V", StringVal("<not nil is fine>")},
 			},
 		},
 		{
 			desc: "circular list",
 			raw:  circular(3),
 			cfg:  CycleTracker,
-			want: target{1, keyvals{
-				{"Value", rawVal("1")},
-				{"Next", keyvals{
-					{"Value", rawVal("2")},
-					{"Next", keyvals{
-						{"Value", rawVal("3")},
-						{"Next", ref{1}},
+			want: Target{1, KeyVals{
+				{"Value", RawVal("1")},
+				{"Next", KeyVals{
+					{"Value", RawVal("2")},
+					{"Next", KeyVals{
+						{"Value", RawVal("3")},
+						{"Next", Ref{1}},
 					}},
 				}},
 			}},
@@ -248,18 +248,18 @@ func TestVal2node(t *testing.T) {
 			desc: "self referential maps",
 			raw:  selfRef(),
 			cfg:  CycleTracker,
-			want: target{1, keyvals{
-				{"ID", rawVal("1")},
-				{"Child", keyvals{
-					{"2", target{2, keyvals{
-						{"ID", rawVal("2")},
-						{"Child", keyvals{
-							{"3", target{3, keyvals{
-								{"ID", rawVal("3")},
-								{"Child", keyvals{
-									{"1", ref{1}},
-									{"2", ref{2}},
-									{"3", ref{3}},
+			want: Target{1, KeyVals{
+				{"ID", RawVal("1")},
+				{"Child", KeyVals{
+					{"2", Target{2, KeyVals{
+						{"ID", RawVal("2")},
+						{"Child", KeyVals{
+							{"3", Target{3, KeyVals{
+								{"ID", RawVal("3")},
+								{"Child", KeyVals{
+									{"1", Ref{1}},
+									{"2", Ref{2}},
+									{"3", Ref{3}},
 								}},
 							}}},
 						}},
@@ -275,21 +275,21 @@ func TestVal2node(t *testing.T) {
 				"3. three": circular(1),
 			},
 			cfg: CycleTracker,
-			want: keyvals{
-				{"1. one", target{1, keyvals{
-					{"Value", rawVal("1")},
-					{"Next", ref{1}},
+			want: KeyVals{
+				{"1. one", Target{1, KeyVals{
+					{"Value", RawVal("1")},
+					{"Next", Ref{1}},
 				}}},
-				{"2. two", target{2, keyvals{
-					{"Value", rawVal("1")},
-					{"Next", keyvals{
-						{"Value", rawVal("2")},
-						{"Next", ref{2}},
+				{"2. two", Target{2, KeyVals{
+					{"Value", RawVal("1")},
+					{"Next", KeyVals{
+						{"Value", RawVal("2")},
+						{"Next", Ref{2}},
 					}},
 				}}},
-				{"3. three", target{3, keyvals{
-					{"Value", rawVal("1")},
-					{"Next", ref{3}},
+				{"3. three", Target{3, KeyVals{
+					{"Value", RawVal("1")},
+					{"Next", Ref{3}},
 				}}},
 			},
 		},
@@ -454,3 +454,178 @@ func (n *nilIsFine) String() string {
 	}
 	return "<not nil is fine>"
 }
+
+type myErr struct{ msg string }
+
+func (e *myErr) Error() string  { return e.msg }
+func (e *myErr) String() string { return "myErr(" + e.msg + ")" }
+
+func TestFormatterInterfaces(t *testing.T) {
+	stringer := reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	errType := reflect.TypeOf((*error)(nil)).Elem()
+	quote := func(prefix string) func(v interface{}) string {
+		return func(v interface{}) string { return prefix + fmt.Sprint(v) }
+	}
+
+	tests := []struct {
+		desc string
+		cfg  *Config
+		raw  interface{}
+		want Node
+	}{
+		{
+			desc: "concrete error type",
+			cfg:  DefaultConfig,
+			raw:  struct{ Err *myErr }{&myErr{"boom"}},
+			want: KeyVals{{"Err", RawVal("boom")}},
+		},
+		{
+			desc: "exact type first",
+			cfg: &Config{Formatter: map[reflect.Type]interface{}{
+				errType:                  quote("error: "),
+				reflect.TypeOf(&myErr{}): quote("exact: "),
+			}},
+			raw:  &myErr{"boom"},
+			want: RawVal("exact: boom"),
+		},
+		{
+			desc: "interfaces by name",
+			cfg: &Config{Formatter: map[reflect.Type]interface{}{
+				stringer: quote("stringer: "),
+				errType:  quote("error: "),
+			}},
+			raw:  &myErr{"boom"},
+			want: RawVal("error: boom"),
+		},
+		{
+			desc: "interface before PrintStringers",
+			cfg: &Config{
+				PrintStringers: true,
+				Formatter:      map[reflect.Type]interface{}{errType: quote("error: ")},
+			},
+			raw:  &myErr{"boom"},
+			want: RawVal("error: boom"),
+		},
+		{
+			desc: "nil interface entry disables stringers",
+			cfg: &Config{
+				PrintStringers: true,
+				Formatter:      map[reflect.Type]interface{}{errType: nil},
+			},
+			raw:  &myErr{"boom"},
+			want: KeyVals{},
+		},
+		{
+			desc: "value does not implement",
+			cfg:  DefaultConfig,
+			raw:  myErr{"boom"},
+			want: KeyVals{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ref := &reflector{
+				Config: test.cfg,
+			}
+			if got, want := ref.val2node(reflect.ValueOf(test.raw)), test.want; !reflect.DeepEqual(got, want) {
+				t.Errorf(" got %#v", got)
+				t.Errorf("want %#v", want)
+			}
+		})
+	}
+}
+
+func TestTypeAnnotations(t *testing.T) {
+	type holder struct {
+		Shape  interface{}
+		Count  int
+		Ptr    *location
+		Labels map[string]interface{}
+	}
+	val := holder{
+		Shape:  circle{Radius: 2},
+		Count:  3,
+		Ptr:    &location{1, 2},
+		Labels: map[string]interface{}{"n": int64(1), "none": nil},
+	}
+
+	tests := []struct {
+		desc  string
+		types TypeMode
+		want  Node
+	}{
+		{
+			desc:  "none",
+			types: NoTypes,
+			want: KeyVals{
+				{"Shape", KeyVals{{"Radius", RawVal("2")}}},
+				{"Count", RawVal("3")},
+				{"Ptr", KeyVals{{"X", RawVal("1")}, {"Y", RawVal("2")}}},
+				{"Labels", KeyVals{{"n", RawVal("1")}, {"none", RawVal("nil")}}},
+			},
+		},
+		{
+			desc:  "interfaces",
+			types: InterfaceTypes,
+			want: KeyVals{
+				{"Shape", Typed{"pretty.circle", KeyVals{{"Radius", RawVal("2")}}}},
+				{"Count", RawVal("3")},
+				{"Ptr", KeyVals{{"X", RawVal("1")}, {"Y", RawVal("2")}}},
+				{"Labels", KeyVals{{"n", Typed{"int64", RawVal("1")}}, {"none", RawVal("nil")}}},
+			},
+		},
+		{
+			desc:  "all",
+			types: AllTypes,
+			want: Typed{"pretty.holder", KeyVals{
+				{"Shape", Typed{"pretty.circle", KeyVals{{"Radius", Typed{"float64", RawVal("2")}}}}},
+				{"Count", Typed{"int", RawVal("3")}},
+				{"Ptr", Typed{"*pretty.location", KeyVals{
+					{"X", Typed{"int", RawVal("1")}},
+					{"Y", Typed{"int", RawVal("2")}},
+				}}},
+				{"Labels", Typed{"map[string]interface {}", KeyVals{
+					{"n", Typed{"int64", RawVal("1")}},
+					{"none", RawVal("nil")},
+				}}},
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			ref := &reflector{
+				Config: &Config{Types: test.types},
+			}
+			if got, want := ref.val2node(reflect.ValueOf(val)), test.want; !reflect.DeepEqual(got, want) {
+				t.Errorf(" got %#v", got)
+				t.Errorf("want %#v", want)
+			}
+		})
+	}
+}
+
+func TestTypeAnnotationsMapKeys(t *testing.T) {
+	val := map[location]interface{}{{1, 2}: circle{Radius: 3}}
+	for _, types := range []TypeMode{InterfaceTypes, AllTypes} {
+		ref := &reflector{
+			Config: &Config{Types: types},
+		}
+		got := ref.val2node(reflect.ValueOf(val))
+		if types == AllTypes {
+			typed, ok := got.(Typed)
+			if !ok {
+				t.Fatalf("AllTypes: got %#v, want a Typed map", got)
+			}
+			got = typed.Value
+		}
+		kvs, ok := got.(KeyVals)
+		if !ok || len(kvs) != 1 {
+			t.Fatalf("types %d: got %#v, want one map entry", types, got)
+		}
+		if got, want := kvs[0].Key, "{X:1,Y:2}"; got != want {
+			t.Errorf("types %d: key = %q, want %q", types, got, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// RegisterFormatter sets the Formatter for values of type T in cfg to format.
+// If T is an interface type, format applies to every type which implements it.
+//
+// Unlike adding to the Formatter map directly, the signature of format is
+// checked at compile time.  The Formatter map is copied before it is modified,
+// so maps which are shared with other Configs, such as DefaultFormatter, are
+// not affected.
+func RegisterFormatter[T any](cfg *Config, format func(T) string) {
+	register[T](cfg, format)
+}
+
+// RegisterSubstitute sets the Formatter for values of type T in cfg to
+// substitute, which returns a value to be printed in place of each value of
+// type T, such as a map[string]interface{} view of it.  See RegisterFormatter.
+func RegisterSubstitute[T, S any](cfg *Config, substitute func(T) S) {
+	register[T](cfg, substitute)
+}
+
+// register sets the Formatter for values of type T in a copy of cfg.Formatter.
+func register[T any](cfg *Config, format interface{}) {
+	formatters := make(map[reflect.Type]interface{}, len(cfg.Formatter)+1)
+	for typ, f := range cfg.Formatter {
+		formatters[typ] = f
+	}
+	formatters[reflect.TypeOf((*T)(nil)).Elem()] = format
+	cfg.Formatter = formatters
+}
+
+// Validate checks that each entry in the Formatter map of cfg can be called
+// with values of its type and returns a result.  If not, it returns an error
+// describing the first such entry, in order of type names.
+//
+// Fprint validates cfg before printing.  Other functions print a description of
+// the problem in place of each value which would use an invalid entry.
+func (cfg *Config) Validate() error {
+	types := make([]reflect.Type, 0, len(cfg.Formatter))
+	for typ := range cfg.Formatter {
+		types = append(types, typ)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].String() < types[j].String()
+	})
+	for _, typ := range types {
+		if err := checkFormatter(typ, cfg.Formatter[typ]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkFormatter returns an error if format cannot be used as the Formatter for
+// values of type typ.  A nil format is valid.
+func checkFormatter(typ reflect.Type, format interface{}) error {
+	if format == nil {
+		return nil
+	}
+	fn := reflect.TypeOf(format)
+	switch {
+	case fn.Kind() != reflect.Func:
+		return fmt.Errorf("pretty: Formatter for %v is a %v, not a func", typ, fn)
+	case !callableWith(fn, typ):
+		return fmt.Errorf("pretty: Formatter for %v: %v cannot be called with a %v", typ, fn, typ)
+	case fn.NumOut() == 0:
+		return fmt.Errorf("pretty: Formatter for %v: %v does not return a result", typ, fn)
+	}
+	return nil
+}
+
+// callableWith returns whether the func type fn can be called with a single
+// argument of type arg.
+func callableWith(fn, arg reflect.Type) bool {
+	switch n := fn.NumIn(); {
+	case !fn.IsVariadic():
+		return n == 1 && arg.AssignableTo(fn.In(0))
+	case n == 1:
+		return arg.AssignableTo(fn.In(0).Elem())
+	case n == 2:
+		return arg.AssignableTo(fn.In(0))
+	}
+	return false
+}
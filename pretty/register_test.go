@@ -0,0 +1,276 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisterFormatter(t *testing.T) {
+	shared := map[reflect.Type]interface{}{
+		reflect.TypeOf(time.Time{}): fmt.Sprint,
+	}
+	cfg := &Config{Compact: true, Formatter: shared}
+	RegisterFormatter(cfg, func(d time.Duration) string { return "~" + d.String() })
+	RegisterFormatter(cfg, func(err error) string { return "error: " + err.Error() })
+
+	if got, want := len(shared), 1; got != want {
+		t.Errorf("after RegisterFormatter, len(shared) = %d, want %d", got, want)
+	}
+
+	val := struct {
+		D   time.Duration
+		Err *myErr
+	}{time.Second, &myErr{"boom"}}
+	if got, want := cfg.Sprint(val), `{D:~1s,Err:error: boom}`; got != want {
+		t.Errorf("Sprint = %q, want %q", got, want)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate: %s", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	type name string
+	timeType := reflect.TypeOf(time.Time{})
+
+	tests := []struct {
+		desc   string
+		format interface{}
+		err    string
+	}{
+		{
+			desc: "nil",
+		},
+		{
+			desc:   "exact",
+			format: func(t time.Time) string { return "" },
+		},
+		{
+			desc:   "variadic",
+			format: fmt.Sprint,
+		},
+		{
+			desc:   "extra results",
+			format: func(t time.Time) (string, error) { return "", nil },
+		},
+		{
+			desc:   "named string result",
+			format: func(t time.Time) name { return "" },
+		},
+		{
+			desc:   "interface parameter",
+			format: func(s fmt.Stringer) string { return "" },
+		},
+		{
+			desc:   "not a func",
+			format: "2006-01-02",
+			err:    "pretty: Formatter for time.Time is a string, not a func",
+		},
+		{
+			desc:   "wrong parameter",
+			format: func(i int) string { return "" },
+			err:    "pretty: Formatter for time.Time: func(int) string cannot be called with a time.Time",
+		},
+		{
+			desc:   "too many parameters",
+			format: func(t time.Time, layout string) string { return "" },
+			err:    "pretty: Formatter for time.Time: func(time.Time, string) string cannot be called with a time.Time",
+		},
+		{
+			desc:   "substitute result",
+			format: func(t time.Time) []int { return nil },
+		},
+		{
+			desc:   "no result",
+			format: func(t time.Time) {},
+			err:    "pretty: Formatter for time.Time: func(time.Time) does not return a result",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cfg := &Config{Formatter: map[reflect.Type]interface{}{timeType: test.format}}
+			err := cfg.Validate()
+			if got, want := fmt.Sprint(err), test.err; test.err != "" && got != want {
+				t.Errorf("Validate = %q, want %q", got, want)
+			}
+			if test.err == "" && err != nil {
+				t.Errorf("Validate = %q, want success", err)
+			}
+		})
+	}
+}
+
+func TestInvalidFormatter(t *testing.T) {
+	cfg := &Config{Compact: true, Formatter: map[reflect.Type]interface{}{
+		reflect.TypeOf(0): func(s string) string { return s },
+	}}
+
+	want := `[<pretty: Formatter for int: func(string) string cannot be called with a int>]`
+	if got := cfg.Sprint([]int{1}); got != want {
+		t.Errorf("Sprint = %q, want %q", got, want)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := cfg.Fprint(buf, 1); err == nil || !strings.Contains(err.Error(), "cannot be called") {
+		t.Errorf("Fprint error = %v, want a description of the Formatter", err)
+	}
+	if buf.Len() > 0 {
+		t.Errorf("Fprint wrote %q, want nothing", buf.String())
+	}
+}
+
+type tagSet []string
+
+type reading struct {
+	celsius float64
+	sensor  string
+}
+
+func TestSubstitutes(t *testing.T) {
+	cfg := &Config{Diffable: true}
+	RegisterSubstitute(cfg, func(r reading) map[string]interface{} {
+		return map[string]interface{}{
+			"celsius": r.celsius,
+			"sensor":  r.sensor,
+		}
+	})
+	RegisterSubstitute(cfg, func(tags tagSet) tagSet {
+		sorted := append(tagSet(nil), tags...)
+		sort.Strings(sorted)
+		return sorted
+	})
+	RegisterSubstitute(cfg, func(err error) interface{} { return nil })
+
+	val := struct {
+		Reading reading
+		Tags    tagSet
+		Err     error
+	}{
+		Reading: reading{21.5, "attic"},
+		Tags:    tagSet{"b", "c", "a"},
+		Err:     fmt.Errorf("ignored"),
+	}
+	want := strings.Trim(`
+{
+ Reading: {
+  celsius: 21.5,
+  sensor: "attic",
+ },
+ Tags: [
+  "a",
+  "b",
+  "c",
+ ],
+ Err: nil,
+}
+	`, "\n\t")
+	if got := cfg.Sprint(val); got != want {
+		t.Errorf("Sprint:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	other := val
+	other.Tags = tagSet{"c", "a", "b"}
+	if !cfg.Equal(val, other) {
+		t.Errorf("Equal with reordered substitute = false, want true")
+	}
+}
+
+type codeError int
+
+func (c codeError) Error() string { return fmt.Sprintf("code %d", int(c)) }
+
+type wrappedError struct{ Err error }
+
+type tree struct {
+	V    int
+	Kids []tree
+}
+
+func (w wrappedError) Error() string { return "wrapped: " + w.Err.Error() }
+
+func TestSubstituteRecursion(t *testing.T) {
+	pointers := &Config{Compact: true}
+	RegisterSubstitute(pointers, func(l location) *location { return &l })
+
+	wrappers := &Config{Compact: true}
+	RegisterSubstitute(wrappers, func(err error) interface{} { return wrappedError{err} })
+
+	trees := &Config{Compact: true}
+	RegisterSubstitute(trees, func(t tree) map[string]interface{} {
+		return map[string]interface{}{"value": t.V, "kids": t.Kids}
+	})
+
+	treePointers := &Config{Compact: true}
+	RegisterSubstitute(treePointers, func(t tree) *tree {
+		if t.Kids == nil {
+			t.Kids = []tree{}
+		}
+		return &t
+	})
+
+	tests := []struct {
+		desc string
+		cfg  *Config
+		val  interface{}
+		want string
+	}{
+		{
+			desc: "pointer to self",
+			cfg:  pointers,
+			val:  []location{{1, 2}},
+			want: "[{X:1,Y:2}]",
+		},
+		{
+			desc: "interface implemented by substitute",
+			cfg:  wrappers,
+			val:  []error{codeError(3)},
+			want: `[{Err:3}]`,
+		},
+		{
+			desc: "nested substitutes",
+			cfg:  wrappers,
+			val:  []error{wrappedError{codeError(3)}},
+			want: `[{Err:{Err:{Err:3}}}]`,
+		},
+		{
+			desc: "recursive type",
+			cfg:  trees,
+			val:  tree{V: 1, Kids: []tree{{V: 2}, {V: 3, Kids: []tree{{V: 4}}}}},
+			want: `{kids:[{kids:[],value:2},{kids:[{kids:[],value:4}],value:3}],value:1}`,
+		},
+		{
+			desc: "recursive pointer to self",
+			cfg:  treePointers,
+			val:  tree{V: 1, Kids: []tree{{V: 2}}},
+			want: `{V:1,Kids:[{V:2,Kids:[]}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if got, want := test.cfg.Sprint(test.val), test.want; got != want {
+				t.Errorf("Sprint = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,552 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A PathStep is a single step along a Path: a Field, an Index, or a MapKey.
+type PathStep interface {
+	// String returns the step as it would be written in Go, such as ".Name",
+	// "[3]", or "[\"key\"]".
+	String() string
+}
+
+// A Field is a PathStep to the named field of a struct.
+type Field string
+
+func (f Field) String() string { return "." + string(f) }
+
+// An Index is a PathStep to an element of a slice or array.
+type Index int
+
+func (i Index) String() string { return fmt.Sprintf("[%d]", int(i)) }
+
+// A MapKey is a PathStep to an entry of a map.
+type MapKey struct {
+	// Key is the key of the entry, from whichever value contains it.
+	Key reflect.Value
+
+	// Repr is the compact representation of the key.  Keys are matched up
+	// between the compared maps by their representations.
+	Repr string
+}
+
+func (k MapKey) String() string {
+	if k.Key.Kind() == reflect.String {
+		return "[" + strconv.Quote(k.Repr) + "]"
+	}
+	return "[" + k.Repr + "]"
+}
+
+// A Path locates a value within a top-level value.  The empty Path refers to the
+// top-level value itself.
+type Path []PathStep
+
+// String returns the steps of the path written together, such as
+// ".Users[3].Email".
+func (p Path) String() string {
+	var sb strings.Builder
+	for _, step := range p {
+		sb.WriteString(step.String())
+	}
+	return sb.String()
+}
+
+// A DifferenceKind describes how two values differ at a Path.
+type DifferenceKind int
+
+// Kinds of differences.
+const (
+	Changed      DifferenceKind = iota // Both values exist, but differ.
+	Added                              // Only the b value exists.
+	Removed                            // Only the a value exists.
+	TypeMismatch                       // The values are of different types.
+)
+
+var differenceKindNames = map[DifferenceKind]string{
+	Changed:      "changed",
+	Added:        "added",
+	Removed:      "removed",
+	TypeMismatch: "type mismatch",
+}
+
+func (k DifferenceKind) String() string {
+	if name, ok := differenceKindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("DifferenceKind(%d)", int(k))
+}
+
+// A Difference is a single location at which two values differ.
+type Difference struct {
+	Path Path
+	Kind DifferenceKind
+
+	// A and B are the differing values.  The value which is missing from an
+	// Added or Removed difference is the zero Value.  Note that values found
+	// in unexported fields (see IncludeUnexported) do not support Interface.
+	A, B reflect.Value
+}
+
+// visit is a pair of references being compared, for cycle detection.
+type visit struct {
+	a, b       uintptr
+	aLen, bLen int // slices which share a backing array differ by length
+	typ        reflect.Type
+}
+
+// comparer walks two values in parallel and records their differences.
+type comparer struct {
+	*Config
+	*reflector // for determining how values are represented, and the path
+
+	visiting map[visit]bool // references being compared on the current path
+	diffs    []Difference
+	first    bool // stop at the first difference
+}
+
+func newComparer(cfg *Config) *comparer {
+	return &comparer{
+		Config:    cfg,
+		reflector: cfg.newReflector(),
+		visiting:  make(map[visit]bool),
+	}
+}
+
+// report records a difference at the current path.
+func (c *comparer) report(kind DifferenceKind, a, b reflect.Value) {
+	c.diffs = append(c.diffs, Difference{
+		Path: append(Path(nil), c.path...),
+		Kind: kind,
+		A:    a,
+		B:    b,
+	})
+}
+
+// leaf compares a and b by their representations.
+func (c *comparer) leaf(a, b reflect.Value) {
+	if compact(c.repr(a)) == compact(c.repr(b)) {
+		return
+	}
+	if a.IsValid() && b.IsValid() && a.Kind() != b.Kind() {
+		c.report(TypeMismatch, a, b)
+		return
+	}
+	c.report(Changed, a, b)
+}
+
+// repr returns the representation of val at the current path.  References are
+// numbered afresh each time, so that equal values have equal representations.
+func (c *comparer) repr(val reflect.Value) Node {
+	if c.pointerTracker != nil {
+		c.pointerTracker = new(pointerTracker)
+	}
+	return c.val2node(val)
+}
+
+// basicEqual returns whether a and b, which are of the same kind and have no
+// overridden representation, would be represented the same way.  It returns
+// false for ok unless that can be decided without building them.
+func basicEqual(a, b reflect.Value) (equal, ok bool) {
+	if a.Type() != b.Type() {
+		return false, false // the types may be shown
+	}
+	switch a.Kind() {
+	case reflect.Bool:
+		return a.Bool() == b.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		// NaN is represented as itself, and negative zero as -0.
+		x, y := a.Float(), b.Float()
+		return x == y && math.Signbit(x) == math.Signbit(y) || x != x && y != y, true
+	case reflect.String:
+		return a.String() == b.String(), true
+	}
+	return false, false
+}
+
+// compact returns the one-line representation of n.
+func compact(n Node) string {
+	buf := new(bytes.Buffer)
+	newFormatter(&Config{Compact: true}, buf).write(n)
+	return buf.String()
+}
+
+// enter marks the references a and b as being compared on the current path,
+// and returns false if they already are, which means that they refer to
+// themselves.  Unless it returns false, it must be followed by a call to leave.
+//
+// References which are compared in more than one place, but not within
+// themselves, are compared in each place, so that their differences are
+// reported at every path.
+func (c *comparer) enter(a, b reflect.Value) (visit, bool) {
+	v := visit{a: a.Pointer(), b: b.Pointer(), typ: a.Type()}
+	if a.Kind() == reflect.Slice {
+		v.aLen, v.bLen = a.Len(), b.Len()
+	}
+	if c.visiting[v] {
+		return v, false
+	}
+	c.visiting[v] = true
+	return v, true
+}
+
+// leave marks the references of v as no longer being compared.
+func (c *comparer) leave(v visit) {
+	delete(c.visiting, v)
+}
+
+// done returns whether the comparison can stop early.
+func (c *comparer) done() bool {
+	return c.first && len(c.diffs) > 0
+}
+
+func (c *comparer) compare(a, b reflect.Value) {
+	if c.done() {
+		return
+	}
+	switch aRedacted, bRedacted := redacts(a), redacts(b); {
+	case aRedacted && bRedacted:
+		// Redacted values are never shown, so they can't differ.
+		return
+	case aRedacted || bRedacted:
+		// Only one of them is shown, so they differ.
+		c.report(Changed, a, b)
+		return
+	}
+	if c.StrictTypes && a.IsValid() && b.IsValid() && a.Type() != b.Type() {
+		c.report(TypeMismatch, a, b)
+		return
+	}
+	if !a.IsValid() || !b.IsValid() || a.Kind() != b.Kind() {
+		c.leaf(a, b)
+		return
+	}
+
+	_, aOverride := c.override(a)
+	_, bOverride := c.override(b)
+	if aOverride || bOverride {
+		c.leaf(a, b)
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			c.leaf(a, b)
+			return
+		}
+		if a.Kind() == reflect.Ptr {
+			v, ok := c.enter(a, b)
+			if !ok {
+				return
+			}
+			defer c.leave(v)
+		}
+		c.compare(a.Elem(), b.Elem())
+	case reflect.Slice, reflect.Array:
+		if a.Kind() == reflect.Slice && a.Len() > 0 && b.Len() > 0 {
+			v, ok := c.enter(a, b)
+			if !ok {
+				return
+			}
+			defer c.leave(v)
+		}
+		c.compareLists(a, b)
+	case reflect.Map:
+		if a.Len() > 0 && b.Len() > 0 {
+			v, ok := c.enter(a, b)
+			if !ok {
+				return
+			}
+			defer c.leave(v)
+		}
+		c.compareMaps(a, b)
+	case reflect.Struct:
+		c.compareStructs(a, b)
+	default:
+		if equal, ok := basicEqual(a, b); ok {
+			if !equal {
+				c.report(Changed, a, b)
+			}
+			return
+		}
+		c.leaf(a, b)
+	}
+}
+
+func (c *comparer) compareLists(a, b reflect.Value) {
+	for i := 0; (i < a.Len() || i < b.Len()) && !c.done(); i++ {
+		c.push(Index(i))
+		switch {
+		case i >= b.Len():
+			c.report(Removed, a.Index(i), reflect.Value{})
+		case i >= a.Len():
+			c.report(Added, reflect.Value{}, b.Index(i))
+		default:
+			c.compare(a.Index(i), b.Index(i))
+		}
+		c.pop()
+	}
+}
+
+func (c *comparer) compareMaps(a, b reflect.Value) {
+	// Match up keys by their representation, as they would be printed.
+	keyOf := func(key reflect.Value) string {
+		return new(formatter).compactString(c.repr(key))
+	}
+	aKeys := make(map[string]reflect.Value)
+	bKeys := make(map[string]reflect.Value)
+	var names []string
+	for _, key := range a.MapKeys() {
+		name := keyOf(key)
+		aKeys[name] = key
+		names = append(names, name)
+	}
+	for _, key := range b.MapKeys() {
+		name := keyOf(key)
+		if _, ok := aKeys[name]; !ok {
+			names = append(names, name)
+		}
+		bKeys[name] = key
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if c.done() {
+			return
+		}
+		aKey, inA := aKeys[name]
+		bKey, inB := bKeys[name]
+		key := aKey
+		if !inA {
+			key = bKey
+		}
+		if c.redactsKey(key) {
+			continue
+		}
+		c.push(MapKey{key, name})
+		inA = inA && !c.ignored(a.MapIndex(aKey))
+		inB = inB && !c.ignored(b.MapIndex(bKey))
+		switch {
+		case !inA && !inB:
+			// ignored on both sides
+		case !inB:
+			c.report(Removed, a.MapIndex(aKey), reflect.Value{})
+		case !inA:
+			c.report(Added, reflect.Value{}, b.MapIndex(bKey))
+		default:
+			c.compare(a.MapIndex(aKey), b.MapIndex(bKey))
+		}
+		c.pop()
+	}
+}
+
+func (c *comparer) compareStructs(a, b reflect.Value) {
+	// Match up fields by name, in case the types differ.
+	fields := func(val reflect.Value) (names []string, vals map[string]reflect.Value) {
+		vals = make(map[string]reflect.Value)
+		typ := val.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			sf, field := typ.Field(i), val.Field(i)
+			c.push(Field(sf.Name))
+			// Redacted values are never shown, so they can't differ.
+			if c.includeField(typ, sf, field) && !c.redactsField(sf) {
+				names = append(names, sf.Name)
+				vals[sf.Name] = field
+			}
+			c.pop()
+		}
+		return names, vals
+	}
+	aNames, aVals := fields(a)
+	bNames, bVals := fields(b)
+	for _, name := range bNames {
+		if _, ok := aVals[name]; !ok {
+			aNames = append(aNames, name)
+		}
+	}
+
+	for _, name := range aNames {
+		if c.done() {
+			return
+		}
+		aField, inA := aVals[name]
+		bField, inB := bVals[name]
+		c.push(Field(name))
+		switch {
+		case !inB:
+			c.report(Removed, aField, reflect.Value{})
+		case !inA:
+			c.report(Added, reflect.Value{}, bField)
+		default:
+			c.compare(aField, bField)
+		}
+		c.pop()
+	}
+}
+
+// Differences returns the differences between a and b, using the
+// CompareConfig.  See Config.Differences for details.
+func Differences(a, b interface{}) []Difference {
+	return CompareConfig.Differences(a, b)
+}
+
+// Differences returns the differences between a and b according to cfg, in the
+// order in which they are found.
+//
+// Unlike Compare, Differences does not diff the text representations of a and
+// b.  Instead, it walks both values in parallel, matching struct fields by
+// name, map entries by key, and list elements by index, and records each place
+// at which they differ.  Values which are printed as a single string, such as
+// those handled by a Formatter, are compared by their representations.
+//
+// If a and b are equal, nil is returned.
+func (cfg *Config) Differences(a, b interface{}) []Difference {
+	c := newComparer(cfg)
+	c.compare(reflect.ValueOf(a), reflect.ValueOf(b))
+	return c.diffs
+}
+
+// Equal returns whether a and b are equal, using the CompareConfig.  See
+// Config.Equal for details.
+func Equal(a, b interface{}) bool {
+	return CompareConfig.Equal(a, b)
+}
+
+// Equal returns whether a and b are equal according to cfg, which is to say
+// whether Differences would find no differences between them.  The comparison
+// stops at the first difference, and nothing is rendered except the values
+// which must be compared by their representations.
+func (cfg *Config) Equal(a, b interface{}) bool {
+	c := newComparer(cfg)
+	c.first = true
+	c.compare(reflect.ValueOf(a), reflect.ValueOf(b))
+	return len(c.diffs) == 0
+}
+
+// Diff returns a description of the differences between a and b, using the
+// CompareConfig.  See Config.Diff for details.
+func Diff(a, b interface{}) string {
+	return CompareConfig.Diff(a, b)
+}
+
+// Diff returns a description of the Differences between a and b according to
+// cfg, with one difference per line.  Each is described by its path from the
+// top-level value, along with the compact representations of the values found
+// there:
+//
+//	.Users[3].Email: "a" -> "b"
+//	.Crew["Arthur Dent"]: removed "Along for the Ride"
+//	.Crew["Rowan Artosok"]: added "Captain"
+//
+// If a and b are equal, the empty string is returned.
+func (cfg *Config) Diff(a, b interface{}) string {
+	var lines []string
+	for _, d := range cfg.Differences(a, b) {
+		prefix := ""
+		if len(d.Path) > 0 {
+			prefix = d.Path.String() + ": "
+		}
+		switch d.Kind {
+		case Removed:
+			lines = append(lines, prefix+"removed "+compact(cfg.diffNode(d, d.A)))
+		case Added:
+			lines = append(lines, prefix+"added "+compact(cfg.diffNode(d, d.B)))
+		default:
+			a, b := cfg.diffNode(d, d.A), cfg.diffNode(d, d.B)
+			lines = append(lines, prefix+compact(a)+" -> "+compact(b))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// node returns the representation under cfg of val, found at path.
+func (cfg *Config) node(path Path, val reflect.Value) Node {
+	ref := cfg.newReflector()
+	ref.path = append(ref.path, path...)
+	return ref.val2node(val)
+}
+
+// diffNode returns the representation of one of the values of d.  With
+// StrictTypes, the values of a TypeMismatch are annotated with their types,
+// since their representations may well be the same.
+func (cfg *Config) diffNode(d Difference, val reflect.Value) Node {
+	if !cfg.StrictTypes || d.Kind != TypeMismatch {
+		return cfg.node(d.Path, val)
+	}
+	for val.Kind() == reflect.Interface && !val.IsNil() {
+		val = val.Elem()
+	}
+	if !val.IsValid() || val.Kind() == reflect.Interface {
+		return cfg.node(d.Path, val)
+	}
+	return Typed{val.Type().String(), cfg.node(d.Path, val)}
+}
+
+// CompareStructure returns a diff-style rendering of the differences between
+// a and b, using the CompareConfig.  See Config.CompareStructure for details.
+func CompareStructure(a, b interface{}) string {
+	return CompareConfig.CompareStructure(a, b)
+}
+
+// CompareStructure returns a diff-style rendering of the Differences between
+// a and b according to cfg.  Each is rendered as its path followed by the
+// value from the a side on lines prefixed by '-' and the value from the b side
+// on lines prefixed by '+':
+//
+//	-.Users[3].Email: "a"
+//	+.Users[3].Email: "b"
+//
+// Values are rendered as by Sprint, so they span multiple lines unless cfg is
+// Compact.  If a and b are equal, the empty string is returned.
+func (cfg *Config) CompareStructure(a, b interface{}) string {
+	buf := new(bytes.Buffer)
+	write := func(prefix byte, d Difference, val reflect.Value) {
+		rendered := new(bytes.Buffer)
+		f := newFormatter(cfg, rendered)
+		f.theme = cfg.colors(nil)
+		f.write(cfg.diffNode(d, val))
+		for i, line := range strings.Split(rendered.String(), "\n") {
+			buf.WriteByte(prefix)
+			if i == 0 && len(d.Path) > 0 {
+				buf.WriteString(d.Path.String())
+				buf.WriteString(": ")
+			}
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	}
+	for _, d := range cfg.Differences(a, b) {
+		if d.Kind != Added {
+			write('-', d, d.A)
+		}
+		if d.Kind != Removed {
+			write('+', d, d.B)
+		}
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,477 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type user struct {
+	Name  string
+	Email string
+	Tags  []string
+	Meta  map[string]int
+	Boss  *user
+	Extra interface{}
+}
+
+type point struct{ X int }
+
+type count int
+
+var strictConfig = &Config{
+	Diffable:          true,
+	IncludeUnexported: true,
+	Formatter:         DefaultFormatter,
+	StrictTypes:       true,
+}
+
+func TestStructuralDiff(t *testing.T) {
+	tests := []struct {
+		desc string
+		cfg  *Config
+		a, b interface{}
+		diff string
+	}{
+		{
+			desc: "equal",
+			a:    user{Name: "Zaphod", Tags: []string{"president"}},
+			b:    user{Name: "Zaphod", Tags: []string{"president"}},
+		},
+		{
+			desc: "scalars",
+			a:    1,
+			b:    2,
+			diff: `1 -> 2`,
+		},
+		{
+			desc: "field",
+			a:    []user{{Name: "Ford"}, {Email: "a"}},
+			b:    []user{{Name: "Ford"}, {Email: "b"}},
+			diff: `[1].Email: "a" -> "b"`,
+		},
+		{
+			desc: "list lengths",
+			a:    user{Tags: []string{"a", "b", "c"}},
+			b:    user{Tags: []string{"a", "x"}},
+			diff: strings.TrimSpace(`
+.Tags[1]: "b" -> "x"
+.Tags[2]: removed "c"
+			`),
+		},
+		{
+			desc: "map keys",
+			a:    user{Meta: map[string]int{"age": 42, "heads": 1}},
+			b:    user{Meta: map[string]int{"age": 42, "heads": 2, "arms": 3}},
+			diff: strings.TrimSpace(`
+.Meta["arms"]: added 3
+.Meta["heads"]: 1 -> 2
+			`),
+		},
+		{
+			desc: "non-string map keys",
+			a:    map[[2]int]string{{0, 0}: "origin"},
+			b:    map[[2]int]string{{0, 0}: "start"},
+			diff: `[[0,0]]: "origin" -> "start"`,
+		},
+		{
+			desc: "pointers",
+			a:    &user{Boss: &user{Name: "Zaphod"}},
+			b:    &user{Boss: &user{Name: "Trillian"}},
+			diff: `.Boss.Name: "Zaphod" -> "Trillian"`,
+		},
+		{
+			desc: "nil pointer",
+			a:    user{Boss: &user{Name: "Zaphod"}},
+			b:    user{},
+			diff: `.Boss: {Name:"Zaphod",Email:"",Tags:[],Meta:{},Boss:nil,Extra:nil} -> nil`,
+		},
+		{
+			desc: "interfaces",
+			a:    user{Extra: []int{1, 2}},
+			b:    user{Extra: []int{1, 3}},
+			diff: `.Extra[1]: 2 -> 3`,
+		},
+		{
+			desc: "interface kinds",
+			a:    user{Extra: []int{1, 2}},
+			b:    user{Extra: "12"},
+			diff: `.Extra: [1,2] -> "12"`,
+		},
+		{
+			desc: "struct types",
+			a:    struct{ A, B int }{1, 2},
+			b:    struct{ B, C int }{3, 4},
+			diff: strings.TrimSpace(`
+.A: removed 1
+.B: 2 -> 3
+.C: added 4
+			`),
+		},
+		{
+			desc: "formatter",
+			a:    struct{ T time.Time }{time.Unix(0, 0).UTC()},
+			b:    struct{ T time.Time }{time.Unix(60, 0).UTC()},
+			diff: `.T: 1970-01-01 00:00:00 +0000 UTC -> 1970-01-01 00:01:00 +0000 UTC`,
+		},
+		{
+			desc: "unexported",
+			cfg:  DefaultConfig,
+			a:    struct{ A, b int }{1, 2},
+			b:    struct{ A, b int }{1, 3},
+		},
+		{
+			desc: "cycles",
+			cfg:  CycleTracker,
+			a:    circular(3),
+			b:    circular(3),
+		},
+		{
+			desc: "same representation",
+			a:    user{Extra: int32(1)},
+			b:    user{Extra: int64(1)},
+		},
+		{
+			desc: "strict types",
+			cfg:  strictConfig,
+			a:    user{Extra: int32(1)},
+			b:    user{Extra: int64(1)},
+			diff: `.Extra: int32(1) -> int64(1)`,
+		},
+		{
+			desc: "strict named types",
+			cfg:  strictConfig,
+			a:    []interface{}{struct{ X int }{1}},
+			b:    []interface{}{point{1}},
+			diff: `[0]: struct { X int }{X:1} -> pretty.point{X:1}`,
+		},
+		{
+			desc: "strict equal",
+			cfg:  strictConfig,
+			a:    user{Extra: int32(1), Tags: []string{"a"}},
+			b:    user{Extra: int32(1), Tags: []string{"a"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cfg := test.cfg
+			if cfg == nil {
+				cfg = CompareConfig
+			}
+			if got, want := cfg.Diff(test.a, test.b), test.diff; got != want {
+				t.Errorf("Diff:\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestCompareStructure(t *testing.T) {
+	a := user{Name: "Zaphod", Meta: map[string]int{"heads": 2}}
+	b := user{Name: "Zaphod", Meta: map[string]int{"heads": 1}, Boss: &user{Name: "Trillian"}}
+
+	want := strings.TrimSpace(`
+-.Meta["heads"]: 2
++.Meta["heads"]: 1
+-.Boss: nil
++.Boss: {
++ Name: "Trillian",
++ Email: "",
++ Tags: [
++ ],
++ Meta: {
++ },
++ Boss: nil,
++ Extra: nil,
++}
+	`)
+	if got := CompareStructure(a, b); got != want {
+		t.Errorf("CompareStructure:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := CompareStructure(a, a); got != "" {
+		t.Errorf("CompareStructure(a, a) = %q, want empty", got)
+	}
+}
+
+func TestDifferences(t *testing.T) {
+	type summary struct {
+		path string
+		kind DifferenceKind
+	}
+	tests := []struct {
+		desc string
+		a, b interface{}
+		want []summary
+	}{
+		{
+			desc: "equal",
+			a:    user{Name: "Zaphod"},
+			b:    user{Name: "Zaphod"},
+		},
+		{
+			desc: "changed",
+			a:    []user{{Email: "a"}},
+			b:    []user{{Email: "b"}},
+			want: []summary{{"[0].Email", Changed}},
+		},
+		{
+			desc: "added and removed",
+			a:    user{Tags: []string{"a", "b"}, Meta: map[string]int{"x": 1}},
+			b:    user{Tags: []string{"a"}, Meta: map[string]int{"x": 1, "y": 2}},
+			want: []summary{
+				{".Tags[1]", Removed},
+				{`.Meta["y"]`, Added},
+			},
+		},
+		{
+			desc: "type mismatch",
+			a:    user{Extra: 42},
+			b:    user{Extra: "42"},
+			want: []summary{{".Extra", TypeMismatch}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var got []summary
+			for _, d := range Differences(test.a, test.b) {
+				got = append(got, summary{d.Path.String(), d.Kind})
+			}
+			if diff := Compare(got, test.want); diff != "" {
+				t.Errorf("Differences: (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSharedReferences(t *testing.T) {
+	type halves struct {
+		Head, All []int
+	}
+	type pair struct {
+		First, Second *point
+	}
+	a, b := []int{1, 2, 3}, []int{1, 9, 9}
+	pa, pb := &point{1}, &point{2}
+	cyclic := circular(2)
+
+	tests := []struct {
+		desc string
+		a, b interface{}
+		want []string
+	}{
+		{
+			desc: "shared backing array",
+			a:    halves{a[:1], a},
+			b:    halves{b[:1], b},
+			want: []string{".All[1]", ".All[2]"},
+		},
+		{
+			desc: "shared pointer",
+			a:    pair{pa, pa},
+			b:    pair{pb, pb},
+			want: []string{".First.X", ".Second.X"},
+		},
+		{
+			desc: "cycles",
+			a:    cyclic,
+			b:    circular(2),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			var got []string
+			for _, d := range Differences(test.a, test.b) {
+				got = append(got, d.Path.String())
+			}
+			if diff := Compare(got, test.want); diff != "" {
+				t.Errorf("Differences: (-got +want)\n%s", diff)
+			}
+			if got, want := Equal(test.a, test.b), len(test.want) == 0; got != want {
+				t.Errorf("Equal = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDifferenceValues(t *testing.T) {
+	a := map[string]int{"heads": 2, "arms": 3}
+	b := map[string]int{"heads": 1}
+
+	diffs := Differences(a, b)
+	if got, want := len(diffs), 2; got != want {
+		t.Fatalf("len(Differences) = %d, want %d", got, want)
+	}
+
+	removed := diffs[0]
+	if got, want := removed.Kind, Removed; got != want {
+		t.Errorf("diffs[0].Kind = %v, want %v", got, want)
+	}
+	if key, ok := removed.Path[0].(MapKey); !ok || key.Key.String() != "arms" {
+		t.Errorf("diffs[0].Path[0] = %#v, want MapKey for %q", removed.Path[0], "arms")
+	}
+	if removed.A.Int() != 3 || removed.B.IsValid() {
+		t.Errorf("diffs[0] values = %v, %v; want 3 and invalid", removed.A, removed.B)
+	}
+
+	changed := diffs[1]
+	if got, want := changed.Kind, Changed; got != want {
+		t.Errorf("diffs[1].Kind = %v, want %v", got, want)
+	}
+	if changed.A.Int() != 2 || changed.B.Int() != 1 {
+		t.Errorf("diffs[1] values = %v, %v; want 2 and 1", changed.A, changed.B)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	type secret struct {
+		Public  string
+		private int
+	}
+	tests := []struct {
+		desc string
+		cfg  *Config
+		a, b interface{}
+		want bool
+	}{
+		{
+			desc: "equal",
+			a:    user{Name: "Zaphod", Tags: []string{"president"}},
+			b:    user{Name: "Zaphod", Tags: []string{"president"}},
+			want: true,
+		},
+		{
+			desc: "different",
+			a:    user{Name: "Zaphod", Meta: map[string]int{"heads": 2}},
+			b:    user{Name: "Zaphod", Meta: map[string]int{"heads": 1}},
+			want: false,
+		},
+		{
+			desc: "unexported ignored",
+			cfg:  &Config{},
+			a:    secret{"x", 1},
+			b:    secret{"x", 2},
+			want: true,
+		},
+		{
+			desc: "unexported included",
+			cfg:  &Config{IncludeUnexported: true},
+			a:    secret{"x", 1},
+			b:    secret{"x", 2},
+			want: false,
+		},
+		{
+			desc: "formatter",
+			cfg: &Config{Formatter: map[reflect.Type]interface{}{
+				reflect.TypeOf(time.Time{}): func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+			}},
+			a:    time.Date(2001, 1, 1, 12, 0, 0, 0, time.UTC),
+			b:    time.Date(2001, 1, 1, 13, 0, 0, 0, time.FixedZone("CET", 3600)),
+			want: true,
+		},
+		{
+			desc: "NaN",
+			a:    []float64{math.NaN()},
+			b:    []float64{math.NaN()},
+			want: true,
+		},
+		{
+			desc: "negative zero",
+			a:    []float64{0},
+			b:    []float64{math.Copysign(0, -1)},
+			want: false,
+		},
+		{
+			desc: "basic kinds",
+			a:    []interface{}{true, -1, uint8(2), uintptr(3), 4.5, "six"},
+			b:    []interface{}{true, -1, uint8(2), uintptr(3), 4.5, "six"},
+			want: true,
+		},
+		{
+			desc: "named types",
+			cfg:  &Config{},
+			a:    []interface{}{count(3)},
+			b:    []interface{}{3},
+			want: true,
+		},
+		{
+			desc: "named types shown",
+			cfg:  &Config{Types: AllTypes},
+			a:    []interface{}{count(3)},
+			b:    []interface{}{3},
+			want: false,
+		},
+		{
+			desc: "skip zero fields",
+			cfg:  &Config{SkipZeroFields: true},
+			a: struct {
+				Name string
+				Age  int
+			}{Name: "Ford"},
+			b: struct {
+				Name string
+			}{Name: "Ford"},
+			want: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			cfg := test.cfg
+			if cfg == nil {
+				cfg = CompareConfig
+			}
+			if got, want := cfg.Equal(test.a, test.b), test.want; got != want {
+				t.Errorf("Equal = %v, want %v", got, want)
+			}
+			if got, want := len(cfg.Differences(test.a, test.b)) == 0, test.want; got != want {
+				t.Errorf("Equal disagrees with Differences: %v vs %v", want, got)
+			}
+		})
+	}
+}
+
+func BenchmarkEqual(b *testing.B) {
+	benchmarks := []struct {
+		desc string
+		a, b interface{}
+	}{
+		{
+			desc: "ints",
+			a:    make([]int, 1000),
+			b:    make([]int, 1000),
+		},
+		{
+			desc: "structs",
+			a:    []user{{Name: "Zaphod", Tags: []string{"president"}}, {Name: "Ford"}},
+			b:    []user{{Name: "Zaphod", Tags: []string{"president"}}, {Name: "Ford"}},
+		},
+	}
+
+	for _, bench := range benchmarks {
+		b.Run(bench.desc, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Equal(bench.a, bench.b)
+			}
+		})
+	}
+}
@@ -29,6 +29,8 @@ type formatter struct {
 	*bufio.Writer
 	*Config
 
+	theme *Theme // colors for the output, which are empty if it isn't colored
+
 	// Self-referential structure tracking
 	tagNumbers map[int]int // tagNumbers[id] = <#n>
 }
@@ -39,12 +41,24 @@ func newFormatter(cfg *Config, w io.Writer) *formatter {
 	return &formatter{
 		Writer:     bufio.NewWriter(w),
 		Config:     cfg,
+		theme:      noColors,
 		tagNumbers: make(map[int]int),
 	}
 }
 
-func (f *formatter) write(n node) {
+func (f *formatter) write(n Node) {
 	defer f.Flush()
+	switch {
+	case f.JSON:
+		f.writeJSON(n, "")
+		return
+	case f.YAML:
+		f.writeYAML(n)
+		return
+	case f.Width > 0 && !f.Compact && !f.Diffable:
+		f.writeLayout(f.newLayout(n), 0, 0)
+		return
+	}
 	n.format(f, "")
 }
 
@@ -60,15 +74,19 @@ func (f *formatter) tagFor(id int) int {
 	return tag
 }
 
-type node interface {
+// A Node is an element of the tree into which values are converted before they
+// are printed.  Trees are produced by Reflect and printed by SprintNode and
+// FprintNode, and the types which implement Node are those in this package:
+// StringVal, RawVal, KeyVals, List, Ref, Target, Typed, and CompactVal.
+type Node interface {
 	format(f *formatter, indent string)
 }
 
-func (f *formatter) compactString(n node) string {
+func (f *formatter) compactString(n Node) string {
 	switch k := n.(type) {
-	case stringVal:
+	case StringVal:
 		return string(k)
-	case rawVal:
+	case RawVal:
 		return string(k)
 	}
 
@@ -79,26 +97,51 @@ func (f *formatter) compactString(n node) string {
 	return buf.String()
 }
 
-type stringVal string
+// writeShort writes n on one line if it is at most max characters long there,
+// and returns whether it did.
+func (f *formatter) writeShort(n Node, max int) bool {
+	short := f.compactString(n)
+	if len(short) > max {
+		return false
+	}
+	if f.theme == noColors {
+		f.WriteString(short)
+	} else {
+		f.writeCompact(n)
+	}
+	return true
+}
+
+// A StringVal is a string, which is printed quoted.
+type StringVal string
 
-func (str stringVal) format(f *formatter, indent string) {
-	f.WriteString(strconv.Quote(string(str)))
+func (str StringVal) format(f *formatter, indent string) {
+	f.paint(f.theme.String, strconv.Quote(string(str)))
 }
 
-type rawVal string
+// A RawVal is printed as-is, such as a number or the result of a Formatter.
+type RawVal string
 
-func (r rawVal) format(f *formatter, indent string) {
-	f.WriteString(string(r))
+func (r RawVal) format(f *formatter, indent string) {
+	f.paint(f.theme.rawColor(string(r)), string(r))
 }
 
-type keyval struct {
-	key string
-	val node
+// A KeyVal is a named element of a KeyVals.
+type KeyVal struct {
+	Key string
+	Val Node
 }
 
-type keyvals []keyval
+// KeyVals is a struct or a map, whose fields or entries are printed in order.
+type KeyVals []KeyVal
+
+func (l KeyVals) format(f *formatter, indent string) {
+	// Short structs and maps are spread out when Diffable, so that changes to
+	// them don't change the lines they are on.
+	if max := f.ShortStruct; max > 0 && !f.Compact && !f.Diffable && f.writeShort(l, max) {
+		return
+	}
 
-func (l keyvals) format(f *formatter, indent string) {
 	f.WriteByte('{')
 
 	switch {
@@ -108,9 +151,9 @@ func (l keyvals) format(f *formatter, indent string) {
 			if i > 0 {
 				f.WriteByte(',')
 			}
-			f.WriteString(kv.key)
+			f.paint(f.theme.Key, kv.Key)
 			f.WriteByte(':')
-			kv.val.format(f, indent)
+			kv.Val.format(f, indent)
 		}
 	case f.Diffable:
 		f.WriteByte('\n')
@@ -118,16 +161,16 @@ func (l keyvals) format(f *formatter, indent string) {
 		// Each value gets its own line:
 		for _, kv := range l {
 			f.WriteString(inner)
-			f.WriteString(kv.key)
+			f.paint(f.theme.Key, kv.Key)
 			f.WriteString(": ")
-			kv.val.format(f, inner)
+			kv.Val.format(f, inner)
 			f.WriteString(",\n")
 		}
 		f.WriteString(indent)
 	default:
 		keyWidth := 0
 		for _, kv := range l {
-			if kw := len(kv.key); kw > keyWidth {
+			if kw := len(kv.Key); kw > keyWidth {
 				keyWidth = kw
 			}
 		}
@@ -140,25 +183,22 @@ func (l keyvals) format(f *formatter, indent string) {
 				f.WriteString(",\n")
 				f.WriteString(alignKey)
 			}
-			f.WriteString(kv.key)
+			f.paint(f.theme.Key, kv.Key)
 			f.WriteString(": ")
-			f.WriteString(alignValue[len(kv.key):])
-			kv.val.format(f, inner)
+			f.WriteString(alignValue[len(kv.Key):])
+			kv.Val.format(f, inner)
 		}
 	}
 
 	f.WriteByte('}')
 }
 
-type list []node
+// A List is a slice or an array.
+type List []Node
 
-func (l list) format(f *formatter, indent string) {
-	if max := f.ShortList; max > 0 {
-		short := f.compactString(l)
-		if len(short) <= max {
-			f.WriteString(short)
-			return
-		}
+func (l List) format(f *formatter, indent string) {
+	if max := f.ShortList; max > 0 && f.writeShort(l, max) {
+		return
 	}
 
 	f.WriteByte('[')
@@ -197,27 +237,71 @@ func (l list) format(f *formatter, indent string) {
 	f.WriteByte(']')
 }
 
-type ref struct {
-	id int
+// A Ref refers back to the Target with the same ID, which contains it.  Refs
+// are produced when TrackCycles is set and a value refers to itself.
+type Ref struct {
+	ID int
 }
 
-func (r ref) format(f *formatter, indent string) {
-	fmt.Fprintf(f, "<see #%d>", f.tagFor(r.id))
+func (r Ref) format(f *formatter, indent string) {
+	f.paint(f.theme.Cycle, fmt.Sprintf("<see #%d>", f.tagFor(r.ID)))
 }
 
-type target struct {
-	id    int
-	value node
+// A Target is a value referred to by a Ref with the same ID.  The IDs of Refs
+// and Targets are arbitrary; they are numbered in order when printed.
+type Target struct {
+	ID    int
+	Value Node
 }
 
-func (t target) format(f *formatter, indent string) {
-	tag := fmt.Sprintf("<#%d> ", f.tagFor(t.id))
+func (t Target) format(f *formatter, indent string) {
+	tag := fmt.Sprintf("<#%d>", f.tagFor(t.ID))
 	switch {
 	case f.Diffable, f.Compact:
 		// no indent changes
 	default:
-		indent += strings.Repeat(" ", len(tag))
+		indent += strings.Repeat(" ", len(tag)+1)
 	}
-	f.WriteString(tag)
-	t.value.format(f, indent)
+	f.paint(f.theme.Cycle, tag)
+	f.WriteByte(' ')
+	t.Value.format(f, indent)
+}
+
+// A Typed annotates a value with the name of its type, as in a conversion.
+type Typed struct {
+	Type  string
+	Value Node
+}
+
+func (t Typed) format(f *formatter, indent string) {
+	open := ""
+	switch t.Value.(type) {
+	case KeyVals, List:
+	default:
+		open = "("
+		defer f.WriteByte(')')
+	}
+	switch {
+	case f.Diffable, f.Compact:
+		// no indent changes
+	default:
+		indent += strings.Repeat(" ", len(t.Type)+len(open))
+	}
+	f.paint(f.theme.Type, t.Type)
+	f.WriteString(open)
+	t.Value.format(f, indent)
+}
+
+// A CompactVal is a value which is always printed on one line.
+type CompactVal struct {
+	Value Node
+}
+
+func (c CompactVal) format(f *formatter, indent string) {
+	cfg := *f.Config
+	cfg.Compact = true
+	saved := f.Config
+	f.Config = &cfg
+	defer func() { f.Config = saved }()
+	c.Value.format(f, indent)
 }
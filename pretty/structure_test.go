@@ -23,7 +23,7 @@ import (
 func TestFormat(t *testing.T) {
 	tests := []struct {
 		desc string
-		node node
+		node Node
 
 		// All strings have a leading newline trimmed before comparison:
 		normal   string
@@ -31,21 +31,21 @@ func TestFormat(t *testing.T) {
 	}{
 		{
 			desc:     "string",
-			node:     stringVal("zaphod"),
+			node:     StringVal("zaphod"),
 			normal:   `"zaphod"`,
 			diffable: `"zaphod"`,
 		},
 		{
 			desc:     "raw",
-			node:     rawVal("42"),
+			node:     RawVal("42"),
 			normal:   `42`,
 			diffable: `42`,
 		},
 		{
 			desc: "keyvals",
-			node: keyvals{
-				{"name", stringVal("zaphod")},
-				{"age", rawVal("42")},
+			node: KeyVals{
+				{"name", StringVal("zaphod")},
+				{"age", RawVal("42")},
 			},
 			normal: `
 {name: "zaphod",
@@ -58,7 +58,7 @@ func TestFormat(t *testing.T) {
 		},
 		{
 			desc: "empty list",
-			node: list{},
+			node: List{},
 			normal: `
 []`,
 			diffable: `
@@ -67,7 +67,7 @@ func TestFormat(t *testing.T) {
 		},
 		{
 			desc: "empty nested list",
-			node: list{list{}},
+			node: List{List{}},
 			normal: `
 [[]]`,
 			diffable: `
@@ -78,9 +78,9 @@ func TestFormat(t *testing.T) {
 		},
 		{
 			desc: "list",
-			node: list{
-				stringVal("zaphod"),
-				rawVal("42"),
+			node: List{
+				StringVal("zaphod"),
+				RawVal("42"),
 			},
 			normal: `
 ["zaphod",
@@ -93,7 +93,7 @@ func TestFormat(t *testing.T) {
 		},
 		{
 			desc: "empty keyvals",
-			node: keyvals{},
+			node: KeyVals{},
 			normal: `
 {}`,
 			diffable: `
@@ -102,7 +102,7 @@ func TestFormat(t *testing.T) {
 		},
 		{
 			desc: "empty nested keyvals",
-			node: keyvals{{"k", keyvals{}}},
+			node: KeyVals{{"k", KeyVals{}}},
 			normal: `
 {k: {}}`,
 			diffable: `
@@ -113,20 +113,20 @@ func TestFormat(t *testing.T) {
 		},
 		{
 			desc: "nested",
-			node: list{
-				stringVal("first"),
-				list{rawVal("1"), rawVal("2"), rawVal("3")},
-				keyvals{
-					{"trillian", keyvals{
-						{"race", stringVal("human")},
-						{"age", rawVal("36")},
+			node: List{
+				StringVal("first"),
+				List{RawVal("1"), RawVal("2"), RawVal("3")},
+				KeyVals{
+					{"trillian", KeyVals{
+						{"race", StringVal("human")},
+						{"age", RawVal("36")},
 					}},
-					{"zaphod", keyvals{
-						{"occupation", stringVal("president of the galaxy")},
-						{"features", stringVal("two heads")},
+					{"zaphod", KeyVals{
+						{"occupation", StringVal("president of the galaxy")},
+						{"features", StringVal("two heads")},
 					}},
 				},
-				keyvals{},
+				KeyVals{},
 			},
 			normal: `
 ["first",
@@ -162,13 +162,13 @@ func TestFormat(t *testing.T) {
 		},
 		{
 			desc: "recursive",
-			node: target{1, keyvals{
-				{"Value", rawVal("1")},
-				{"Next", keyvals{
-					{"Value", rawVal("2")},
-					{"Next", keyvals{
-						{"Value", rawVal("3")},
-						{"Next", ref{1}},
+			node: Target{1, KeyVals{
+				{"Value", RawVal("1")},
+				{"Next", KeyVals{
+					{"Value", RawVal("2")},
+					{"Next", KeyVals{
+						{"Value", RawVal("3")},
+						{"Next", Ref{1}},
 					}},
 				}},
 			}},
@@ -191,12 +191,12 @@ func TestFormat(t *testing.T) {
 		},
 		{
 			desc: "print in order",
-			node: list{
-				target{2, keyvals{
-					{"Next", ref{1}},
+			node: List{
+				Target{2, KeyVals{
+					{"Next", Ref{1}},
 				}},
-				target{1, keyvals{
-					{"Next", ref{2}},
+				Target{1, KeyVals{
+					{"Next", Ref{2}},
 				}},
 			},
 			normal: `
@@ -210,6 +210,29 @@ func TestFormat(t *testing.T) {
  <#2> {
   Next: <see #1>,
  },
+]`,
+		},
+		{
+			desc: "typed",
+			node: List{
+				Typed{"shape.Circle", KeyVals{
+					{"Center", Typed{"shape.Point", KeyVals{{"X", RawVal("1")}, {"Y", RawVal("2")}}}},
+					{"Radius", Typed{"float64", RawVal("3")}},
+				}},
+			},
+			normal: `
+[shape.Circle{Center: shape.Point{X: 1,
+                                  Y: 2},
+              Radius: float64(3)}]`,
+			diffable: `
+[
+ shape.Circle{
+  Center: shape.Point{
+   X: 1,
+   Y: 2,
+  },
+  Radius: float64(3),
+ },
 ]`,
 		},
 	}
@@ -237,50 +260,50 @@ func TestFormat(t *testing.T) {
 
 func TestCompactString(t *testing.T) {
 	tests := []struct {
-		node
+		node    Node
 		compact string
 	}{
 		{
-			stringVal("abc"),
+			StringVal("abc"),
 			"abc",
 		},
 		{
-			rawVal("2"),
+			RawVal("2"),
 			"2",
 		},
 		{
-			list{
-				rawVal("2"),
-				rawVal("3"),
+			List{
+				RawVal("2"),
+				RawVal("3"),
 			},
 			"[2,3]",
 		},
 		{
-			keyvals{
-				{"name", stringVal("zaphod")},
-				{"age", rawVal("42")},
+			KeyVals{
+				{"name", StringVal("zaphod")},
+				{"age", RawVal("42")},
 			},
 			`{name:"zaphod",age:42}`,
 		},
 		{
-			list{
-				list{
-					rawVal("0"),
-					rawVal("1"),
-					rawVal("2"),
-					rawVal("3"),
+			List{
+				List{
+					RawVal("0"),
+					RawVal("1"),
+					RawVal("2"),
+					RawVal("3"),
 				},
-				list{
-					rawVal("1"),
-					rawVal("2"),
-					rawVal("3"),
-					rawVal("0"),
+				List{
+					RawVal("1"),
+					RawVal("2"),
+					RawVal("3"),
+					RawVal("0"),
 				},
-				list{
-					rawVal("2"),
-					rawVal("3"),
-					rawVal("0"),
-					rawVal("1"),
+				List{
+					RawVal("2"),
+					RawVal("3"),
+					RawVal("0"),
+					RawVal("1"),
 				},
 			},
 			`[[0,1,2,3],[1,2,3,0],[2,3,0,1]]`,
@@ -300,28 +323,28 @@ func TestShortList(t *testing.T) {
 	}
 
 	tests := []struct {
-		node
+		node Node
 		want string
 	}{
 		{
-			list{
-				list{
-					rawVal("0"),
-					rawVal("1"),
-					rawVal("2"),
-					rawVal("3"),
+			List{
+				List{
+					RawVal("0"),
+					RawVal("1"),
+					RawVal("2"),
+					RawVal("3"),
 				},
-				list{
-					rawVal("1"),
-					rawVal("2"),
-					rawVal("3"),
-					rawVal("0"),
+				List{
+					RawVal("1"),
+					RawVal("2"),
+					RawVal("3"),
+					RawVal("0"),
 				},
-				list{
-					rawVal("2"),
-					rawVal("3"),
-					rawVal("0"),
-					rawVal("1"),
+				List{
+					RawVal("2"),
+					RawVal("3"),
+					RawVal("0"),
+					RawVal("1"),
 				},
 			},
 			`[[0,1,2,3],
@@ -339,17 +362,81 @@ func TestShortList(t *testing.T) {
 	}
 }
 
-var benchNode = keyvals{
-	{"list", list{
-		rawVal("0"),
-		rawVal("1"),
-		rawVal("2"),
-		rawVal("3"),
+func TestShortStruct(t *testing.T) {
+	node := KeyVals{
+		{"Name", StringVal("ford")},
+		{"Home", KeyVals{{"X", RawVal("1")}, {"Y", RawVal("2")}}},
+		{"Tags", List{StringVal("hoopy"), StringVal("frood")}},
+	}
+
+	tests := []struct {
+		desc string
+		cfg  *Config
+		want string
+	}{
+		{
+			desc: "short",
+			cfg:  &Config{ShortStruct: 16},
+			want: `
+{Name: "ford",
+ Home: {X:1,Y:2},
+ Tags: ["hoopy",
+        "frood"]}`,
+		},
+		{
+			desc: "with short lists",
+			cfg:  &Config{ShortStruct: 16, ShortList: 20},
+			want: `
+{Name: "ford",
+ Home: {X:1,Y:2},
+ Tags: ["hoopy","frood"]}`,
+		},
+		{
+			desc: "all short",
+			cfg:  &Config{ShortStruct: 64},
+			want: `
+{Name:"ford",Home:{X:1,Y:2},Tags:["hoopy","frood"]}`,
+		},
+		{
+			desc: "diffable",
+			cfg:  &Config{ShortStruct: 64, Diffable: true},
+			want: `
+{
+ Name: "ford",
+ Home: {
+  X: 1,
+  Y: 2,
+ },
+ Tags: [
+  "hoopy",
+  "frood",
+ ],
+}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			newFormatter(test.cfg, buf).write(node)
+			if got, want := buf.String(), strings.TrimPrefix(test.want, "\n"); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+var benchNode = KeyVals{
+	{"list", List{
+		RawVal("0"),
+		RawVal("1"),
+		RawVal("2"),
+		RawVal("3"),
 	}},
-	{"keyvals", keyvals{
-		{"a", stringVal("b")},
-		{"c", stringVal("e")},
-		{"d", stringVal("f")},
+	{"keyvals", KeyVals{
+		{"a", StringVal("b")},
+		{"c", StringVal("e")},
+		{"d", StringVal("f")},
 	}},
 }
 
@@ -365,7 +452,8 @@ func benchOpts(b *testing.B, cfg *Config) {
 	}
 }
 
-func BenchmarkWriteDefault(b *testing.B)   { benchOpts(b, DefaultConfig) }
-func BenchmarkWriteShortList(b *testing.B) { benchOpts(b, &Config{ShortList: 16}) }
-func BenchmarkWriteCompact(b *testing.B)   { benchOpts(b, &Config{Compact: true}) }
-func BenchmarkWriteDiffable(b *testing.B)  { benchOpts(b, &Config{Diffable: true}) }
+func BenchmarkWriteDefault(b *testing.B)     { benchOpts(b, DefaultConfig) }
+func BenchmarkWriteShortList(b *testing.B)   { benchOpts(b, &Config{ShortList: 16}) }
+func BenchmarkWriteShortStruct(b *testing.B) { benchOpts(b, &Config{ShortStruct: 16}) }
+func BenchmarkWriteCompact(b *testing.B)     { benchOpts(b, &Config{Compact: true}) }
+func BenchmarkWriteDiffable(b *testing.B)    { benchOpts(b, &Config{Diffable: true}) }
@@ -0,0 +1,65 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"reflect"
+	"strings"
+)
+
+// fieldTag holds the options from the `pretty:"..."` tag of a struct field.
+//
+// The tag consists of an optional name followed by comma-separated options:
+//
+//	`pretty:"-"`          the field is skipped
+//	`pretty:"name"`       the field is printed with the given name
+//	`pretty:",omitempty"` the field is skipped if it has a zero value
+//	`pretty:",redact"`    the field's value is replaced by a placeholder
+//	`pretty:",compact"`   the field's value is printed on one line
+type fieldTag struct {
+	name      string
+	skip      bool
+	omitEmpty bool
+	redact    bool
+	compact   bool
+}
+
+// parseTag returns the pretty tag options for sf.
+func parseTag(sf reflect.StructField) fieldTag {
+	tag := fieldTag{name: sf.Name}
+	value, ok := sf.Tag.Lookup("pretty")
+	if !ok {
+		return tag
+	}
+	if value == "-" {
+		tag.skip = true
+		return tag
+	}
+	opts := strings.Split(value, ",")
+	if opts[0] != "" {
+		tag.name = opts[0]
+	}
+	for _, opt := range opts[1:] {
+		switch opt {
+		case "omitempty":
+			tag.omitEmpty = true
+		case "redact":
+			tag.redact = true
+		case "compact":
+			tag.compact = true
+		}
+	}
+	return tag
+}
@@ -0,0 +1,147 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		desc string
+		tag  reflect.StructTag
+		want fieldTag
+	}{
+		{
+			desc: "none",
+			want: fieldTag{name: "Field"},
+		},
+		{
+			desc: "other packages",
+			tag:  `json:"-"`,
+			want: fieldTag{name: "Field"},
+		},
+		{
+			desc: "skip",
+			tag:  `pretty:"-"`,
+			want: fieldTag{name: "Field", skip: true},
+		},
+		{
+			desc: "rename",
+			tag:  `pretty:"field"`,
+			want: fieldTag{name: "field"},
+		},
+		{
+			desc: "options",
+			tag:  `pretty:",omitempty,redact,compact,unknown"`,
+			want: fieldTag{name: "Field", omitEmpty: true, redact: true, compact: true},
+		},
+		{
+			desc: "rename with options",
+			tag:  `pretty:"-,omitempty"`,
+			want: fieldTag{name: "-", omitEmpty: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			sf := reflect.StructField{Name: "Field", Tag: test.tag}
+			if got, want := parseTag(sf), test.want; got != want {
+				t.Errorf("parseTag(%q) = %+v, want %+v", test.tag, got, want)
+			}
+		})
+	}
+}
+
+type location struct{ X, Y int }
+
+type account struct {
+	ID       int      `pretty:"-"`
+	User     string   `pretty:"user"`
+	Nickname string   `pretty:",omitempty"`
+	Password string   `pretty:",redact"`
+	Home     location `pretty:"home,compact"`
+	Aliases  []string
+}
+
+func TestTags(t *testing.T) {
+	tests := []struct {
+		desc string
+		cfg  *Config
+		val  interface{}
+		want string
+	}{
+		{
+			desc: "default",
+			cfg:  DefaultConfig,
+			val: account{
+				ID:       42,
+				User:     "zaphod",
+				Password: "beeblebrox",
+				Home:     location{1, 2},
+				Aliases:  []string{"z"},
+			},
+			want: strings.Trim(`
+{user:     "zaphod",
+ Password: <redacted>,
+ home:     {X:1,Y:2},
+ Aliases:  ["z"]}
+			`, "\n\t"),
+		},
+		{
+			desc: "omitempty",
+			cfg:  &Config{Compact: true},
+			val:  account{Nickname: "z"},
+			want: `{user:"",Nickname:"z",Password:<redacted>,home:{X:0,Y:0},Aliases:[]}`,
+		},
+		{
+			desc: "diffable",
+			cfg:  &Config{Diffable: true},
+			val:  account{Home: location{1, 2}},
+			want: strings.Trim(`
+{
+ user: "",
+ Password: <redacted>,
+ home: {X:1,Y:2},
+ Aliases: [
+ ],
+}
+			`, "\n\t"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if got, want := test.cfg.Sprint(test.val), test.want; got != want {
+				t.Errorf("Sprint:\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestTagsCompare(t *testing.T) {
+	a := account{ID: 1, User: "ford", Password: "towel"}
+	b := account{ID: 2, User: "ford", Password: "hoopy"}
+	if diff := Diff(a, b); diff != "" {
+		t.Errorf("Diff of skipped and redacted fields:\n%s", diff)
+	}
+
+	b.Nickname = "ix"
+	if got, want := Diff(a, b), `.Nickname: added "ix"`; got != want {
+		t.Errorf("Diff = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+)
+
+// Reflect returns the tree of Nodes for val according to the DefaultConfig.
+func Reflect(val interface{}) Node {
+	return DefaultConfig.Reflect(val)
+}
+
+// Reflect returns the tree of Nodes into which val is converted according to
+// cfg before it is printed.  The tree can be inspected with Walk, modified with
+// Transform, and printed with SprintNode or FprintNode.
+func (cfg *Config) Reflect(val interface{}) Node {
+	return cfg.newReflector().val2node(reflect.ValueOf(val))
+}
+
+// SprintNode returns the representation of the tree n according to the
+// DefaultConfig.
+func SprintNode(n Node) string {
+	return DefaultConfig.SprintNode(n)
+}
+
+// SprintNode returns the representation of the tree n according to cfg.  Only
+// the options of cfg which affect layout, such as Compact, Diffable, and
+// ShortList, apply to a tree which has already been produced.  A nil tree, such
+// as one whose root was removed by Transform, is represented by the empty
+// string.
+func (cfg *Config) SprintNode(n Node) string {
+	if n == nil {
+		return ""
+	}
+	buf := new(bytes.Buffer)
+	f := newFormatter(cfg, buf)
+	f.theme = cfg.colors(nil)
+	f.write(n)
+	return buf.String()
+}
+
+// FprintNode writes the representation of the tree n to w according to cfg.
+// See SprintNode for details.
+func (cfg *Config) FprintNode(w io.Writer, n Node) (int64, error) {
+	if n == nil {
+		return 0, nil
+	}
+	buf := new(bytes.Buffer)
+	f := newFormatter(cfg, buf)
+	f.theme = cfg.colors(w)
+	f.write(n)
+	return buf.WriteTo(w)
+}
+
+// Walk calls visit for n and, if visit returns true, walks each of the
+// children of n in order.  The children of KeyVals are the values of its
+// elements, the children of a List are its elements, and the child of a Target,
+// a Typed, or a CompactVal is its Value.
+func Walk(n Node, visit func(Node) bool) {
+	if n == nil || !visit(n) {
+		return
+	}
+	switch n := n.(type) {
+	case KeyVals:
+		for _, kv := range n {
+			Walk(kv.Val, visit)
+		}
+	case List:
+		for _, elem := range n {
+			Walk(elem, visit)
+		}
+	case Target:
+		Walk(n.Value, visit)
+	case Typed:
+		Walk(n.Value, visit)
+	case CompactVal:
+		Walk(n.Value, visit)
+	}
+}
+
+// Transform returns a copy of the tree n in which each node has been replaced
+// by the result of calling fn on it, after its children have been transformed.
+//
+// If fn returns nil, the node is removed from the KeyVals or List which
+// contains it, along with any Target, Typed, or CompactVal which wraps it.  If
+// the root is removed, Transform returns nil.
+func Transform(n Node, fn func(Node) Node) Node {
+	switch n := n.(type) {
+	case KeyVals:
+		out := make(KeyVals, 0, len(n))
+		for _, kv := range n {
+			if val := Transform(kv.Val, fn); val != nil {
+				out = append(out, KeyVal{kv.Key, val})
+			}
+		}
+		return fn(out)
+	case List:
+		out := make(List, 0, len(n))
+		for _, elem := range n {
+			if elem := Transform(elem, fn); elem != nil {
+				out = append(out, elem)
+			}
+		}
+		return fn(out)
+	case Target:
+		if n.Value = Transform(n.Value, fn); n.Value == nil {
+			return nil
+		}
+		return fn(n)
+	case Typed:
+		if n.Value = Transform(n.Value, fn); n.Value == nil {
+			return nil
+		}
+		return fn(n)
+	case CompactVal:
+		if n.Value = Transform(n.Value, fn); n.Value == nil {
+			return nil
+		}
+		return fn(n)
+	case nil:
+		return nil
+	}
+	return fn(n)
+}
@@ -0,0 +1,148 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReflect(t *testing.T) {
+	val := struct {
+		Name string
+		Tags []string
+	}{"Zaphod", []string{"president"}}
+
+	want := KeyVals{
+		{"Name", StringVal("Zaphod")},
+		{"Tags", List{StringVal("president")}},
+	}
+	got := Reflect(val)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Reflect = %#v, want %#v", got, want)
+	}
+	if got, want := SprintNode(got), Sprint(val); got != want {
+		t.Errorf("SprintNode(Reflect(val)) = %q, want Sprint(val) = %q", got, want)
+	}
+
+	buf := new(bytes.Buffer)
+	cfg := &Config{Compact: true}
+	if _, err := cfg.FprintNode(buf, want); err != nil {
+		t.Fatalf("FprintNode: %s", err)
+	}
+	if got, want := buf.String(), `{Name:"Zaphod",Tags:["president"]}`; got != want {
+		t.Errorf("FprintNode = %q, want %q", got, want)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	tree := KeyVals{
+		{"a", List{RawVal("1"), Target{1, KeyVals{{"b", Ref{1}}}}}},
+		{"c", Typed{"int32", RawVal("2")}},
+		{"d", CompactVal{StringVal("3")}},
+	}
+
+	var visited []string
+	Walk(tree, func(n Node) bool {
+		visited = append(visited, reflect.TypeOf(n).Name())
+		_, isTarget := n.(Target)
+		return !isTarget
+	})
+	want := "KeyVals List RawVal Target Typed RawVal CompactVal StringVal"
+	if got := strings.Join(visited, " "); got != want {
+		t.Errorf("Walk visited %q, want %q", got, want)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	tree := KeyVals{
+		{"id", RawVal("42")},
+		{"name", StringVal("Ford")},
+		{"tags", List{StringVal("a"), RawVal("1"), Typed{"int", RawVal("2")}}},
+		{"self", Target{1, KeyVals{{"id", RawVal("7")}}}},
+	}
+	orig := SprintNode(tree)
+
+	tests := []struct {
+		desc string
+		fn   func(Node) Node
+		want string
+	}{
+		{
+			desc: "identity",
+			fn:   func(n Node) Node { return n },
+			want: `{id:42,name:"Ford",tags:["a",1,int(2)],self:<#1> {id:7}}`,
+		},
+		{
+			desc: "remove raw values",
+			fn: func(n Node) Node {
+				if _, ok := n.(RawVal); ok {
+					return nil
+				}
+				return n
+			},
+			want: `{name:"Ford",tags:["a"],self:<#1> {}}`,
+		},
+		{
+			desc: "rewrite strings",
+			fn: func(n Node) Node {
+				if s, ok := n.(StringVal); ok {
+					return StringVal(strings.ToUpper(string(s)))
+				}
+				return n
+			},
+			want: `{id:42,name:"FORD",tags:["A",1,int(2)],self:<#1> {id:7}}`,
+		},
+		{
+			desc: "remove everything",
+			fn:   func(Node) Node { return nil },
+			want: "",
+		},
+	}
+
+	cfg := &Config{Compact: true}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if got, want := cfg.SprintNode(Transform(tree, test.fn)), test.want; got != want {
+				t.Errorf("Transform:\ngot:  %s\nwant: %s", got, want)
+			}
+			if got := SprintNode(tree); got != orig {
+				t.Errorf("Transform modified its input:\ngot:  %s\nwant: %s", got, orig)
+			}
+		})
+	}
+
+	if got := Transform(tree, func(Node) Node { return nil }); got != nil {
+		t.Errorf("Transform removing everything = %#v, want nil", got)
+	}
+	buf := new(bytes.Buffer)
+	if n, err := cfg.FprintNode(buf, nil); n != 0 || err != nil || buf.Len() > 0 {
+		t.Errorf("FprintNode(nil) = %d, %v and wrote %q; want nothing written", n, err, buf)
+	}
+}
+
+func TestFormatterNode(t *testing.T) {
+	type celsius float64
+	cfg := &Config{Compact: true}
+	RegisterSubstitute(cfg, func(c celsius) Node {
+		return Typed{"°C", RawVal(fmt.Sprint(float64(c)))}
+	})
+	if got, want := cfg.Sprint([]celsius{21.5}), `[°C(21.5)]`; got != want {
+		t.Errorf("Sprint = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// yamlIndent is the indentation added for each level of nesting in YAML.
+const yamlIndent = "  "
+
+// yamlContext is where a YAML node is written.
+type yamlContext int
+
+const (
+	yamlDocument yamlContext = iota // at the start of the document
+	yamlMapValue                    // after "key:"
+	yamlListItem                    // after "-"
+)
+
+// writeYAML writes n as a YAML document.
+func (f *formatter) writeYAML(n Node) {
+	f.writeYAMLValue(n, "", yamlDocument)
+	if !f.Compact && yamlEndsWithBreak(n) {
+		// The final line break of a literal is only kept if it is there.
+		f.WriteByte('\n')
+	}
+}
+
+// yamlEndsWithBreak returns whether n, written in block style, ends with a
+// literal block scalar which ends with a line break.
+func yamlEndsWithBreak(n Node) bool {
+	for {
+		switch w := n.(type) {
+		case Target:
+			n = w.Value
+		case Typed:
+			n = w.Value
+		case KeyVals:
+			if len(w) == 0 {
+				return false
+			}
+			n = w[len(w)-1].Val
+		case List:
+			if len(w) == 0 {
+				return false
+			}
+			n = w[len(w)-1]
+		case StringVal:
+			return yamlLiteral(string(w)) && strings.HasSuffix(string(w), "\n")
+		default:
+			return false
+		}
+	}
+}
+
+// writeYAMLValue writes n in block style, unless it must be written in flow
+// style, following the indicator for ctx.  Lines after the first are indented
+// by indent plus yamlIndent, or indent alone at the start of the document.
+func (f *formatter) writeYAMLValue(n Node, indent string, ctx yamlContext) {
+	n, props, flow := f.yamlUnwrap(n)
+
+	child, lead := indent+yamlIndent, " "
+	if ctx == yamlDocument {
+		child, lead = indent, ""
+	}
+	if props != "" {
+		f.WriteString(lead + props)
+		lead = " "
+	}
+	// A collection starts on the line of its "-" indicator, like a nested
+	// list item, when there is nothing else on that line.
+	inline := props == "" && ctx != yamlMapValue
+
+	if !flow && !f.Compact {
+		switch n := n.(type) {
+		case KeyVals:
+			if len(n) == 0 {
+				break
+			}
+			for i, kv := range n {
+				if i > 0 || !inline {
+					f.WriteByte('\n')
+					f.WriteString(child)
+				} else {
+					f.WriteString(lead)
+				}
+				f.WriteString(yamlString(kv.Key))
+				f.WriteByte(':')
+				f.writeYAMLValue(kv.Val, child, yamlMapValue)
+			}
+			return
+		case List:
+			if len(n) == 0 {
+				break
+			}
+			for i, elem := range n {
+				if i > 0 || !inline {
+					f.WriteByte('\n')
+					f.WriteString(child)
+				} else {
+					f.WriteString(lead)
+				}
+				f.WriteByte('-')
+				f.writeYAMLValue(elem, child, yamlListItem)
+			}
+			return
+		case StringVal:
+			if yamlLiteral(string(n)) {
+				f.WriteString(lead)
+				f.writeYAMLLiteral(string(n), indent+yamlIndent)
+				return
+			}
+		}
+	}
+
+	f.WriteString(lead)
+	f.writeYAMLFlow(n)
+}
+
+// writeYAMLFlow writes n in flow style.
+func (f *formatter) writeYAMLFlow(n Node) {
+	n, props, _ := f.yamlUnwrap(n)
+	if props != "" {
+		f.WriteString(props + " ")
+	}
+
+	switch n := n.(type) {
+	case KeyVals:
+		f.WriteByte('{')
+		for i, kv := range n {
+			if i > 0 {
+				f.WriteString(", ")
+			}
+			f.WriteString(yamlString(kv.Key))
+			f.WriteString(": ")
+			f.writeYAMLFlow(kv.Val)
+		}
+		f.WriteByte('}')
+	case List:
+		f.WriteByte('[')
+		for i, elem := range n {
+			if i > 0 {
+				f.WriteString(", ")
+			}
+			f.writeYAMLFlow(elem)
+		}
+		f.WriteByte(']')
+	case Ref:
+		fmt.Fprintf(f, "*%d", f.tagFor(n.ID))
+	case StringVal:
+		f.WriteString(yamlString(string(n)))
+	case RawVal:
+		f.WriteString(yamlRaw(string(n)))
+	default:
+		f.WriteString(yamlString(f.compactString(n)))
+	}
+}
+
+// yamlUnwrap unwraps the Targets, Typed nodes, and CompactVals around n and
+// returns the node they wrap, the anchor and tag they imply, and whether the
+// node must be written in flow style.
+func (f *formatter) yamlUnwrap(n Node) (inner Node, props string, flow bool) {
+	var parts []string
+	for {
+		switch w := n.(type) {
+		case Target:
+			parts = append(parts, fmt.Sprintf("&%d", f.tagFor(w.ID)))
+			n = w.Value
+		case Typed:
+			parts = append(parts, "!"+yamlTag(w.Type))
+			n = w.Value
+		case CompactVal:
+			flow = true
+			n = w.Value
+		default:
+			return n, strings.Join(parts, " "), flow
+		}
+	}
+}
+
+// writeYAMLLiteral writes s, which must satisfy yamlLiteral, as a literal
+// block scalar with its lines at the given indentation.
+func (f *formatter) writeYAMLLiteral(s, indent string) {
+	text := strings.TrimRight(s, "\n")
+	switch trailing := len(s) - len(text); {
+	case trailing == 0:
+		f.WriteString("|-")
+	case trailing == 1:
+		f.WriteString("|")
+	default:
+		f.WriteString("|+")
+		text = s[:len(s)-1]
+	}
+	for _, line := range strings.Split(text, "\n") {
+		f.WriteByte('\n')
+		if line != "" {
+			f.WriteString(indent)
+			f.WriteString(line)
+		}
+	}
+}
+
+// yamlLiteral returns whether s should be written as a literal block scalar,
+// which is the case for printable strings of several lines whose first line
+// which is not empty does not start with whitespace, since that would be taken
+// as indentation.
+func yamlLiteral(s string) bool {
+	if !strings.Contains(s, "\n") || strings.TrimRight(s, "\n") == "" {
+		return false
+	}
+	if first := strings.TrimLeft(s, "\n"); first[0] == ' ' || first[0] == '\t' || !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if r != '\n' && r != '\t' && !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlString returns s as a YAML scalar, which is plain unless it could be
+// taken as something other than a string, or would not be valid unquoted in
+// either block or flow style.  Quoted strings are escaped as in JSON, which
+// YAML double-quoted strings are a superset of.
+func yamlString(s string) string {
+	if yamlPlain(s) {
+		return s
+	}
+	return strings.Replace(jsonQuote(s), "\u0085", `\N`, -1)
+}
+
+// yamlPlain returns whether s can be written as a plain scalar.
+func yamlPlain(s string) bool {
+	if s == "" || !utf8.ValidString(s) {
+		return false
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`+.0123456789") {
+		return false
+	}
+	if strings.ContainsAny(s, ",[]{}") || strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return false
+	}
+	if s[0] == ' ' || s[len(s)-1] == ' ' || s[len(s)-1] == ':' {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	switch strings.ToLower(s) {
+	case "~", "null", "true", "false", "yes", "no", "on", "off", "y", "n", "<<", "=":
+		return false
+	}
+	return true
+}
+
+// yamlRaw returns the YAML for a RawVal.  Raw values which are valid numbers
+// or booleans are written as such, nil is written as null, and anything else,
+// such as the address of a func, is written as a string.
+func yamlRaw(s string) string {
+	switch s {
+	case "nil":
+		return "null"
+	case "true", "false":
+		return s
+	case "NaN":
+		return ".nan"
+	case "+Inf":
+		return ".inf"
+	case "-Inf":
+		return "-.inf"
+	}
+	if s != "" && strings.IndexByte("-0123456789", s[0]) >= 0 && json.Valid([]byte(s)) {
+		return s
+	}
+	return yamlString(s)
+}
+
+// yamlTag returns the name of a local tag for the type name typ, with any
+// characters which are not allowed in tags escaped as in URIs.
+func yamlTag(typ string) string {
+	var b strings.Builder
+	for i := 0; i < len(typ); i++ {
+		c := typ[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+			b.WriteByte(c)
+		case strings.IndexByte("-._*/()", c) >= 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,179 @@
+// Copyright 2013 Google Inc.  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pretty
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestYAMLString(t *testing.T) {
+	tests := []struct {
+		desc string
+		in   string
+		want string
+	}{
+		{"plain", "hoopy frood", "hoopy frood"},
+		{"unicode", "café", "café"},
+		{"empty", "", `""`},
+		{"boolean", "yes", `"yes"`},
+		{"null", "Null", `"Null"`},
+		{"number", "1.5", `"1.5"`},
+		{"indicator", "- item", `"- item"`},
+		{"flow", "[x]", `"[x]"`},
+		{"comma", "a, b", `"a, b"`},
+		{"key", "a: b", `"a: b"`},
+		{"comment", "a #b", `"a #b"`},
+		{"trailing colon", "a:", `"a:"`},
+		{"leading space", " a", `" a"`},
+		{"trailing space", "a ", `"a "`},
+		{"control", "a\tb", `"a\tb"`},
+		{"next line", "a\u0085b", `"a\Nb"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if got, want := yamlString(test.in), test.want; got != want {
+				t.Errorf("yamlString(%q) = %s, want %s", test.in, got, want)
+			}
+		})
+	}
+}
+
+func TestYAML(t *testing.T) {
+	type config struct {
+		Name    string
+		Port    int
+		Ratio   float64
+		Debug   bool
+		Script  string
+		Tags    []string
+		Limits  map[string]int
+		Origin  location `pretty:",compact"`
+		Secret  string   `pretty:",redact"`
+		Backend interface{}
+		Hosts   [][]string
+	}
+	val := config{
+		Name:   "web",
+		Port:   8080,
+		Ratio:  math.Inf(1),
+		Script: "#!/bin/sh\necho hi\n",
+		Tags:   []string{"on", "front end"},
+		Limits: map[string]int{"cpu": 2},
+		Hosts:  [][]string{{"a", "b"}, {}},
+	}
+
+	tests := []struct {
+		desc string
+		cfg  Config
+		val  interface{}
+		want string
+	}{
+		{
+			desc: "block",
+			cfg:  Config{YAML: true},
+			val:  val,
+			want: `
+Name: web
+Port: 8080
+Ratio: .inf
+Debug: false
+Script: |
+  #!/bin/sh
+  echo hi
+Tags:
+  - "on"
+  - front end
+Limits:
+  cpu: 2
+Origin: {X: 0, "Y": 0}
+Secret: <redacted>
+Backend: null
+Hosts:
+  - - a
+    - b
+  - []`,
+		},
+		{
+			desc: "flow",
+			cfg:  Config{YAML: true, Compact: true},
+			val:  val,
+			want: `
+{Name: web, Port: 8080, Ratio: .inf, Debug: false, Script: "#!/bin/sh\necho hi\n", Tags: ["on", front end], Limits: {cpu: 2}, Origin: {X: 0, "Y": 0}, Secret: <redacted>, Backend: null, Hosts: [[a, b], []]}`,
+		},
+		{
+			desc: "literals",
+			cfg:  Config{YAML: true},
+			val:  []string{"a\n  b", "a\n\n", " a\nb", "\n  a\nb", "\n\na\nb"},
+			want: `
+- |-
+  a
+    b
+- |+
+  a
+
+- " a\nb"
+- "\n  a\nb"
+- |-
+
+
+  a
+  b`,
+		},
+		{
+			desc: "final line break",
+			cfg:  Config{YAML: true},
+			val:  "a\nb\n",
+			want: `
+|
+  a
+  b
+`,
+		},
+		{
+			desc: "cycles",
+			cfg:  Config{YAML: true, TrackCycles: true},
+			val:  circular(2),
+			want: `
+&1
+Value: 1
+Next:
+  Value: 2
+  Next: *1`,
+		},
+		{
+			desc: "types",
+			cfg:  Config{YAML: true, Types: InterfaceTypes},
+			val:  []interface{}{location{1, 2}, []int{3}, "x"},
+			want: `
+- !pretty.location
+  X: 1
+  "Y": 2
+- !%5B%5Dint
+  - 3
+- !string x`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			if got, want := test.cfg.Sprint(test.val), strings.TrimPrefix(test.want, "\n"); got != want {
+				t.Errorf("Sprint:\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}